@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jochil/gcs/pkg/callgraph"
+	"github.com/jochil/gcs/pkg/search"
+	"github.com/spf13/cobra"
+)
+
+var (
+	callgraphFormat string
+	callgraphOut    string
+
+	callgraphCmd = &cobra.Command{
+		Use:   "callgraph",
+		Args:  cobra.MatchAll(cobra.MinimumNArgs(1), cobra.OnlyValidArgs),
+		Short: "Exports the call graph of all scanned functions",
+		RunE:  runCallgraph,
+	}
+)
+
+func init() {
+	formats := []string{}
+	for _, f := range callgraph.Formats {
+		formats = append(formats, string(f))
+	}
+
+	callgraphCmd.Flags().StringVarP(&callgraphFormat, "format", "f", string(callgraph.FormatDOT), fmt.Sprintf("output format (%s)", strings.Join(formats, "|")))
+	callgraphCmd.Flags().StringVarP(&callgraphOut, "out", "o", "", "output file, prints to stdout if not set")
+	rootCmd.AddCommand(callgraphCmd)
+}
+
+func runCallgraph(cmd *cobra.Command, args []string) error {
+	format, err := callgraph.ParseFormat(callgraphFormat)
+	if err != nil {
+		return err
+	}
+
+	srcPaths := []string{}
+	for _, arg := range args {
+		srcPath, err := filepath.Abs(arg)
+		if err != nil {
+			return err
+		}
+		srcPaths = append(srcPaths, srcPath)
+	}
+
+	result, err := scan(cmd, srcPaths, search.Options{})
+	if err != nil {
+		return err
+	}
+	g := result.Graph
+
+	if callgraphOut == "" {
+		return callgraph.Export(g, cmd.OutOrStdout(), format)
+	}
+
+	file, err := os.Create(callgraphOut)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return callgraph.Export(g, file, format)
+}
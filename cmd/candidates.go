@@ -1,20 +1,33 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"path/filepath"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/jochil/gcs/internal/tui"
-	"github.com/jochil/gcs/pkg/candidate"
 	"github.com/jochil/gcs/pkg/search"
 	"github.com/spf13/cobra"
 )
 
 var (
-	printJSON  bool
-	limit      int
-	extensions []string
+	printJSON     bool
+	limit         int
+	extensions    []string
+	withChurn     bool
+	coverFiles    []string
+	hideFuzzed    bool
+	sinksFile     string
+	configFile    string
+	profile       string
+	normalization string
+	filterExpr    string
+	minScore      float64
+	visibility    []string
+	excludeFunc   string
+	includePaths  []string
+	jobs          int
 
 	versionCmd = &cobra.Command{
 		Use:   "candidates",
@@ -27,10 +40,42 @@ var (
 func init() {
 	versionCmd.Flags().BoolVar(&printJSON, "json", false, "print results as json to stdout")
 	versionCmd.Flags().IntVarP(&limit, "limit", "l", 0, "limit the amount of candidates (after sorting by score)")
-	versionCmd.Flags().StringArrayVar(&extensions, "ext", []string{}, "only parse files with listed extension, flag can be used multiple times")
+	versionCmd.Flags().BoolVar(&hideFuzzed, "hide-fuzzed", false, "hide functions already called by a fuzz target")
+	versionCmd.Flags().StringVar(&filterExpr, "filter", "", `only show candidates matching the expression, eg. 'language == "Go" && metrics.cc >= 5 && !has_fuzz_test'`)
+	versionCmd.Flags().Float64Var(&minScore, "min-score", 0, "hide candidates with a lower score")
+	versionCmd.Flags().StringSliceVar(&visibility, "visibility", []string{}, "only show functions with the visibility (public|private|protected), flag can be used multiple times")
+	versionCmd.Flags().StringVar(&excludeFunc, "exclude-func", "", "hide functions with a name matching the regular expression")
+	versionCmd.Flags().StringArrayVar(&includePaths, "include-path", []string{}, "only show functions in files matching the glob (relative to the scanned directory), flag can be used multiple times")
+	addScoreFlags(versionCmd)
 	rootCmd.AddCommand(versionCmd)
 }
 
+// adds the flags changing the metrics and scores, shared by all commands ranking candidates
+func addScoreFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&extensions, "ext", []string{}, "only parse files with listed extension, flag can be used multiple times")
+	cmd.Flags().BoolVar(&withChurn, "churn", false, "include metrics based on the git history (commits, changed lines, authors)")
+	cmd.Flags().StringArrayVar(&coverFiles, "coverage", []string{}, "coverage report (go coverprofile, lcov, jacoco or cobertura xml), flag can be used multiple times")
+	cmd.Flags().StringVar(&sinksFile, "sinks", "", "yaml file extending the catalog of dangerous sinks")
+	cmd.Flags().StringVar(&configFile, "config", "", "config file with scoring profiles and sinks (default: .gcs.yaml in the scanned or working directory)")
+	cmd.Flags().StringVar(&profile, "profile", "", "scoring profile, built-in profiles are fuzz and unit")
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", 0, "number of workers parsing the files and calculating the metrics (default: GOMAXPROCS)")
+	cmd.Flags().StringVar(&normalization, "normalization", "", "normalization of the metrics (max|minmax|rank|zscore|fixed), fixed keeps scores comparable between runs")
+}
+
+// returns the search options set by the score flags
+func scoreOptions() search.Options {
+	return search.Options{
+		Extensions:    extensions,
+		Churn:         withChurn,
+		CoverageFiles: coverFiles,
+		SinksFile:     sinksFile,
+		ConfigFile:    configFile,
+		Profile:       profile,
+		Normalization: normalization,
+		Jobs:          jobs,
+	}
+}
+
 func run(cmd *cobra.Command, args []string) error {
 	// TODO validate args
 	srcPaths := []string{}
@@ -42,35 +87,68 @@ func run(cmd *cobra.Command, args []string) error {
 		srcPaths = append(srcPaths, srcPath)
 	}
 
-	candidates, err := search.SearchWithOptions(srcPaths, search.Options{
-		Limit:      limit,
-		Extensions: extensions,
-	})
+	opts := scoreOptions()
+	opts.Limit = limit
+	opts.HideFuzzed = hideFuzzed
+	opts.FilterExpression = filterExpr
+	opts.Visibility = visibility
+	opts.ExcludeFunc = excludeFunc
+	opts.IncludePaths = includePaths
+	if cmd.Flags().Changed("min-score") {
+		opts.MinScore = &minScore
+	}
+	if !printJSON {
+		return startTUI(cmd, srcPaths, opts)
+	}
 
+	result, err := scan(cmd, srcPaths, opts)
 	if err != nil {
 		return err
 	}
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(result.Candidates)
+}
 
-	if printJSON {
-		enc := json.NewEncoder(cmd.OutOrStdout())
-		enc.SetIndent("", "  ")
-		err := enc.Encode(candidates)
-		if err != nil {
-			return err
-		}
-	} else {
-		return startTUI(candidates)
+// shows the progress of the scan in the TUI, followed by the list of candidates
+func startTUI(cmd *cobra.Command, srcPaths []string, opts search.Options) error {
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+	program := tea.NewProgram(tui.NewScanModel(cancel))
+
+	type scanResult struct {
+		result *search.Result
+		err    error
 	}
-	return nil
-}
+	results := make(chan scanResult, 1)
+	go func() {
+		opts.Progress = func(p search.Progress) {
+			program.Send(tui.ProgressMsg(p))
+		}
+		result, err := scanContext(ctx, srcPaths, opts)
+		results <- scanResult{result: result, err: err}
 
-func startTUI(candidates candidate.Candidates) error {
-	state, err := tui.NewCandidateModel(candidates)
-	if err != nil {
+		msg := tui.ScanDoneMsg{Err: err}
+		if err == nil {
+			msg.Candidates = result.Candidates
+			msg.Err = skippedError(result)
+		}
+		program.Send(msg)
+	}()
+
+	if _, err := program.Run(); err != nil {
 		return err
 	}
-	if _, err := tea.NewProgram(state).Run(); err != nil {
-		return err
+
+	select {
+	case r := <-results:
+		if r.err != nil {
+			return r.err
+		}
+		// the skipped files are reported after the TUI is closed
+		return reportSkipped(cmd.ErrOrStderr(), r.result)
+	default:
+		// canceled before the scan was done
+		return nil
 	}
-	return nil
 }
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <path> <function>",
+	Args:  cobra.ExactArgs(2),
+	Short: "Explains the score of a function by the contribution of every metric",
+	Long: `Explains the score of a function by the contribution of every metric. The function name can be
+qualified with the class/receiver (eg. MyClass.myMethod).`,
+	RunE: runExplain,
+}
+
+func init() {
+	addScoreFlags(explainCmd)
+	rootCmd.AddCommand(explainCmd)
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	srcPath, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+	funcName := args[1]
+
+	// all candidates are needed for the rank
+	result, err := scan(cmd, []string{srcPath}, scoreOptions())
+	if err != nil {
+		return err
+	}
+	candidates := result.Candidates
+
+	found := false
+	out := cmd.OutOrStdout()
+	for i, c := range candidates {
+		if !matchesFunc(c, funcName) {
+			continue
+		}
+		found = true
+
+		fmt.Fprintf(out, "%s (%s)\n", c, c.Path)
+		fmt.Fprintf(out, "Rank %d of %d, score %.2f (highest score %.2f)\n\n", i+1, len(candidates), c.Score, candidates[0].Score)
+		if err := c.WriteContributions(out); err != nil {
+			return err
+		}
+		fmt.Fprintln(out)
+	}
+	if !found {
+		return fmt.Errorf("no function found matching %s", funcName)
+	}
+	return nil
+}
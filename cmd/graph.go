@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jochil/gcs/pkg/candidate"
+	"github.com/jochil/gcs/pkg/cfg"
+	"github.com/jochil/gcs/pkg/search"
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphFunc   string
+	graphFormat string
+	graphOut    string
+
+	graphCmd = &cobra.Command{
+		Use:   "graph",
+		Args:  cobra.MatchAll(cobra.MinimumNArgs(1), cobra.OnlyValidArgs),
+		Short: "Exports the control flow graph of a function",
+		RunE:  runGraph,
+	}
+)
+
+func init() {
+	formats := []string{}
+	for _, f := range cfg.Formats {
+		formats = append(formats, string(f))
+	}
+
+	graphCmd.Flags().StringVar(&graphFunc, "func", "", "name of the function, can be qualified with the class/receiver (eg. MyClass.myMethod)")
+	graphCmd.Flags().StringVarP(&graphFormat, "format", "f", string(cfg.FormatDOT), fmt.Sprintf("output format (%s)", strings.Join(formats, "|")))
+	graphCmd.Flags().StringVarP(&graphOut, "out", "o", "", "directory for the generated files, prints to stdout if not set")
+	_ = graphCmd.MarkFlagRequired("func")
+	rootCmd.AddCommand(graphCmd)
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	format, err := cfg.ParseFormat(graphFormat)
+	if err != nil {
+		return err
+	}
+
+	srcPaths := []string{}
+	for _, arg := range args {
+		srcPath, err := filepath.Abs(arg)
+		if err != nil {
+			return err
+		}
+		srcPaths = append(srcPaths, srcPath)
+	}
+
+	result, err := scan(cmd, srcPaths, search.Options{
+		Filter: func(c *candidate.Candidate) bool {
+			return matchesFunc(c, graphFunc)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	candidates := result.Candidates
+	if len(candidates) == 0 {
+		return fmt.Errorf("no function found matching %s", graphFunc)
+	}
+
+	if graphOut == "" {
+		return candidates.WriteGraphs(cmd.OutOrStdout(), format)
+	}
+
+	if err := os.MkdirAll(graphOut, 0o755); err != nil {
+		return err
+	}
+	for _, c := range candidates {
+		path, err := c.SaveGraph(graphOut, format)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), path)
+	}
+	return nil
+}
+
+// checks if the candidate matches a function name, optionally qualified by the class name
+func matchesFunc(c *candidate.Candidate, name string) bool {
+	if c.Function.Name == name {
+		return true
+	}
+	return c.Class != nil && c.Class.Name+"."+c.Function.Name == name
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/jochil/gcs/internal/tui"
+	"github.com/jochil/gcs/pkg/search"
+	"golang.org/x/term"
+)
+
+// renders the progress of a scan on a single terminal line
+type progressBar struct {
+	out     io.Writer
+	bar     progress.Model
+	phase   search.Phase
+	updated time.Time
+}
+
+// returns a progress bar if the output is a terminal and not used for verbose logs
+func newProgressBar(out io.Writer) *progressBar {
+	file, ok := out.(*os.File)
+	if !ok || verbose || !term.IsTerminal(int(file.Fd())) {
+		return nil
+	}
+	return &progressBar{out: out, bar: tui.NewProgressBar()}
+}
+
+func (b *progressBar) update(p search.Progress) {
+	// limit the redraws, phase changes are always shown
+	if p.Phase == b.phase && time.Since(b.updated) < 100*time.Millisecond {
+		return
+	}
+	b.phase = p.Phase
+	b.updated = time.Now()
+	fmt.Fprintf(b.out, "\r%s\x1b[K", tui.ProgressView(b.bar, p))
+}
+
+func (b *progressBar) clear() {
+	fmt.Fprint(b.out, "\r\x1b[K")
+}
@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"os/signal"
+	"time"
 
+	"github.com/jochil/gcs/pkg/search"
 	"github.com/spf13/cobra"
 )
 
@@ -27,13 +33,82 @@ var (
 	}
 )
 
+// flags of the scan shared by all commands
+var (
+	strict      bool
+	timeout     time.Duration
+	fileTimeout time.Duration
+)
+
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Show verbose output on console, can be helpful for debugging")
+	rootCmd.PersistentFlags().BoolVar(&strict, "strict", false, "Fail if any file could not be parsed, otherwise these files are skipped")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Cancel the scan after the given duration (eg. 5m)")
+	rootCmd.PersistentFlags().DurationVar(&fileTimeout, "file-timeout", 10*time.Second, "Skip files taking longer to parse, 0 disables the timeout")
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// an interrupt cancels the running scan, the handler is removed afterwards so a second one kills the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
+
+// runs the search with a progress bar on terminals and reports the skipped files, with --strict they are an error
+func scan(cmd *cobra.Command, srcPaths []string, opts search.Options) (*search.Result, error) {
+	bar := newProgressBar(cmd.ErrOrStderr())
+	if bar != nil {
+		opts.Progress = bar.update
+	}
+	result, err := scanContext(cmd.Context(), srcPaths, opts)
+	if bar != nil {
+		bar.clear()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, reportSkipped(cmd.ErrOrStderr(), result)
+}
+
+// runs the search with the timeouts set by the flags
+func scanContext(ctx context.Context, srcPaths []string, opts search.Options) (*search.Result, error) {
+	opts.FileTimeout = fileTimeout
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	result, err := search.ScanContext(ctx, srcPaths, opts)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("scan exceeded the timeout of %s: %w", timeout, err)
+	}
+	return result, err
+}
+
+// prints the skipped files, with --strict they are an error
+func reportSkipped(w io.Writer, result *search.Result) error {
+	if len(result.Skipped) == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "skipped %d files:\n", len(result.Skipped))
+	for _, skipped := range result.Skipped {
+		fmt.Fprintf(w, "  %s\n", skipped)
+	}
+	return skippedError(result)
+}
+
+// returns an error for skipped files if --strict is set
+func skippedError(result *search.Result) error {
+	if strict && len(result.Skipped) > 0 {
+		return fmt.Errorf("%d files could not be parsed", len(result.Skipped))
+	}
+	return nil
+}
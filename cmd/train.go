@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/jochil/gcs/pkg/config"
+	"github.com/jochil/gcs/pkg/train"
+	"github.com/spf13/cobra"
+)
+
+var (
+	trainLabels  string
+	trainOut     string
+	trainProfile string
+
+	trainCmd = &cobra.Command{
+		Use:   "train <path>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Learns the scoring weights from labeled functions",
+		Long: `Learns the scoring weights from labeled functions (eg. functions in which fuzzing found bugs) by a
+logistic regression. The labels are read from a csv (path,function,label) or json file, the fitted weights
+are written as profile to a config file.`,
+		RunE: runTrain,
+	}
+)
+
+func init() {
+	trainCmd.Flags().StringVar(&trainLabels, "labels", "", "csv or json file with the labeled functions")
+	trainCmd.Flags().StringVarP(&trainOut, "out", "o", "", "config file the profile is written to, existing files are updated (default: the config used by the scan or "+config.Filename+")")
+	trainCmd.Flags().StringVar(&trainProfile, "name", "trained", "name of the written profile")
+	_ = trainCmd.MarkFlagRequired("labels")
+	addScoreFlags(trainCmd)
+	rootCmd.AddCommand(trainCmd)
+}
+
+func runTrain(cmd *cobra.Command, args []string) error {
+	srcPath, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+
+	labels, err := train.ReadLabels(trainLabels)
+	if err != nil {
+		return err
+	}
+
+	opts := scoreOptions()
+	scanned, err := scan(cmd, []string{srcPath}, opts)
+	if err != nil {
+		return err
+	}
+	candidates := scanned.Candidates
+
+	result, err := train.Train(candidates, labels, train.DefaultOptions)
+	if err != nil {
+		return err
+	}
+
+	// the config used by the scan gets the profile, otherwise a new one is created in the working directory
+	out := trainOut
+	if out == "" {
+		out = scanned.ConfigFile
+	}
+	if out == "" {
+		out = config.Filename
+	}
+	// the weights only fit to the normalization used for the training and must not be changed by the
+	// weights applied to all profiles
+	profile := &config.Profile{
+		Weights:       result.Weights,
+		Normalization: string(scanned.Scoring.Normalization),
+		Standalone:    true,
+	}
+	if err := config.SaveProfile(out, trainProfile, profile); err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	fmt.Fprintf(w, "Trained on %d candidates (%d positive), %d labels without candidate\n", result.Samples, result.Positives, len(result.Unmatched))
+	fmt.Fprintf(w, "Log loss %.3f, accuracy %.0f%%\n", result.LogLoss, result.Accuracy*100)
+	fmt.Fprintf(w, "Profile %s written to %s, use it with --profile %s\n", trainProfile, out, trainProfile)
+	return nil
+}
@@ -3,6 +3,8 @@ package tui
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -10,6 +12,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jochil/gcs/pkg/candidate"
 	"github.com/jochil/gcs/pkg/generator"
+	"github.com/jochil/gcs/pkg/sinks"
 	"github.com/jochil/gcs/pkg/types"
 )
 
@@ -47,6 +50,8 @@ func NewCandidateModel(candidates candidate.Candidates) (*model, error) {
 		{Title: "#", Width: 4},
 		{Title: "Function", Width: 40},
 		{Title: "Score", Width: 5},
+		{Title: "Cov", Width: 5},
+		{Title: "Tests", Width: 5},
 	}
 
 	rows := []table.Row{}
@@ -55,6 +60,8 @@ func NewCandidateModel(candidates candidate.Candidates) (*model, error) {
 			fmt.Sprint(i),
 			c.Function.Name,
 			fmt.Sprintf("%.2f", c.Score),
+			coveragePercent(c.Metrics.HasCoverage, c.Metrics.LineCoverage),
+			testKinds(c),
 		})
 	}
 
@@ -125,7 +132,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// TODO doing this in a command?
 				m.state = codeView
 				i, _ := strconv.ParseInt(m.table.SelectedRow()[0], 10, 0)
-				testCode := generator.Render(m.candidates[i])
+				testCode, err := generator.Render(m.candidates[i])
+				if err != nil {
+					testCode = err.Error()
+				}
 				m.code.SetContent(testCode)
 			}
 		}
@@ -182,10 +192,46 @@ func detailsContent(c *candidate.Candidate) string {
 
   # Metrics
   Cyclomatic Complexity:  %d 
+  NPath Complexity:       %d
+  Cognitive Complexity:   %d
+  Halstead Volume:        %.2f
+  Halstead Difficulty:    %.2f
+  Halstead Effort:        %.2f
+  Maintainability Index:  %.2f
   Lines of Code:          %d
+  Logical Lines of Code:  %d
+  Comment Lines:          %d
+  Blank Lines:            %d
   Fuzz Friendly Name:     %t
   Primitive Params Only:  %t
+  Parameters:             %d
+  Raw Input Params:       %d
+  Returns Error:          %t
+  Returns Value:          %t
+  Loops:                  %d
+  Max Loop Depth:         %d
+  Unreachable Blocks:     %d
+  Commits:                %d
+  Lines Changed:          %d
+  Authors:                %d
+  Last Modified:          %s
+  Line Coverage:          %s
+  Branch Coverage:        %s
+  Unit Tests:             %t
+  Fuzz Tests:             %t
+  Tests:                  %s
+  Fan-In:                 %d
+  Fan-Out:                %d
+  Entry Points:           %d
+  Reachable:              %t
+  Sink Calls:             %d %s
+  Param Branches:         %d
+  Tainted Loops:          %d
+  Tainted Indexes:        %d
+  Tainted Sinks:          %d
 
+  # Score %.2f
+%s
 `,
 		c.Function.Name,
 		c.Package,
@@ -197,8 +243,92 @@ func detailsContent(c *candidate.Candidate) string {
 		c.Language,
 		c.Path,
 		c.Metrics.CyclomaticComplexity,
+		c.Metrics.NPathComplexity,
+		c.Metrics.CognitiveComplexity,
+		c.Metrics.HalsteadVolume,
+		c.Metrics.HalsteadDifficulty,
+		c.Metrics.HalsteadEffort,
+		c.Metrics.MaintainabilityIndex,
 		c.Metrics.LinesOfCode,
+		c.Metrics.LogicalLinesOfCode,
+		c.Metrics.CommentLines,
+		c.Metrics.BlankLines,
 		c.Metrics.FuzzFriendlyName,
 		c.Metrics.PrimitiveParametersOnly,
+		c.Metrics.ParameterCount,
+		c.Metrics.RawInputParameters,
+		c.Metrics.ReturnsError,
+		c.Metrics.ReturnsValue,
+		c.Metrics.Loops,
+		c.Metrics.MaxLoopDepth,
+		c.Metrics.UnreachableBlocks,
+		c.Metrics.ChurnCommits,
+		c.Metrics.ChurnLinesChanged,
+		c.Metrics.ChurnAuthors,
+		lastModified(c),
+		coveragePercent(c.Metrics.HasCoverage, c.Metrics.LineCoverage),
+		coveragePercent(c.Metrics.HasCoverage, c.Metrics.BranchCoverage),
+		c.Metrics.HasUnitTest,
+		c.Metrics.HasFuzzTest,
+		strings.Join(c.Tests, ", "),
+		c.Metrics.FanIn,
+		c.Metrics.FanOut,
+		c.Metrics.EntryPoints,
+		c.Metrics.Reachable,
+		c.Metrics.SinkCalls,
+		sinkCategories(c),
+		c.Metrics.ParamBranches,
+		c.Metrics.TaintedLoops,
+		c.Metrics.TaintedIndexes,
+		c.Metrics.TaintedSinks,
+		c.Score,
+		contributions(c),
 	)
 }
+
+// returns the contribution table indented like the other details
+func contributions(c *candidate.Candidate) string {
+	out := &strings.Builder{}
+	if err := c.WriteContributions(out); err != nil {
+		return ""
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	return "  " + strings.Join(lines, "\n  ")
+}
+
+func lastModified(c *candidate.Candidate) string {
+	if c.Metrics.LastModified.IsZero() {
+		return "-"
+	}
+	return c.Metrics.LastModified.Format(time.DateOnly)
+}
+
+func coveragePercent(available bool, ratio float64) string {
+	if !available {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", ratio*100)
+}
+
+// returns a short marker for the kinds of existing tests (u: unit test, f: fuzz test)
+func testKinds(c *candidate.Candidate) string {
+	kinds := ""
+	if c.Metrics.HasUnitTest {
+		kinds += "u"
+	}
+	if c.Metrics.HasFuzzTest {
+		kinds += "f"
+	}
+	return kinds
+}
+
+func sinkCategories(c *candidate.Candidate) string {
+	categories := []string{}
+	for _, name := range sinks.Categories(c.Metrics.Sinks) {
+		categories = append(categories, fmt.Sprintf("%s:%d", name, c.Metrics.Sinks[name]))
+	}
+	if len(categories) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(categories, ", ") + ")"
+}
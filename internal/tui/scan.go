@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jochil/gcs/pkg/candidate"
+	"github.com/jochil/gcs/pkg/search"
+)
+
+// ProgressMsg reports the progress of the running scan
+type ProgressMsg search.Progress
+
+// ScanDoneMsg ends the scan, the candidates are shown if there was no error
+type ScanDoneMsg struct {
+	Candidates candidate.Candidates
+	Err        error
+}
+
+// shows the progress while scanning and switches to the list of candidates once the scan is done
+type scanModel struct {
+	bar      progress.Model
+	progress search.Progress
+	cancel   context.CancelFunc
+	size     *tea.WindowSizeMsg
+}
+
+// NewScanModel creates the model shown while scanning, quitting calls the cancel function of the scan
+func NewScanModel(cancel context.CancelFunc) tea.Model {
+	return &scanModel{bar: NewProgressBar(), cancel: cancel}
+}
+
+// NewProgressBar returns the bar used for the progress of a scan
+func NewProgressBar() progress.Model {
+	return progress.New(progress.WithDefaultGradient(), progress.WithWidth(40))
+}
+
+func (m scanModel) Init() tea.Cmd { return nil }
+
+func (m scanModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.size = &msg
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			m.cancel()
+			return m, tea.Quit
+		}
+	case ProgressMsg:
+		m.progress = search.Progress(msg)
+	case ScanDoneMsg:
+		if msg.Err != nil {
+			return m, tea.Quit
+		}
+		list, err := NewCandidateModel(msg.Candidates)
+		if err != nil {
+			return m, tea.Quit
+		}
+		// the list needs the size of the window for the code view
+		if m.size != nil {
+			return list.Update(*m.size)
+		}
+		return list, nil
+	}
+	return m, nil
+}
+
+func (m scanModel) View() string {
+	return fmt.Sprintf("\n  %s\n", ProgressView(m.bar, m.progress)) + helpStyle.Render("\n  esc: cancel\n")
+}
+
+// ProgressView renders the progress of a scan as a single line
+func ProgressView(bar progress.Model, p search.Progress) string {
+	switch p.Phase {
+	case search.PhaseParse:
+		percent := 0.0
+		if p.Discovered > 0 {
+			percent = float64(p.Parsed) / float64(p.Discovered)
+		}
+		return fmt.Sprintf("%s %d/%d files, %d candidates", bar.ViewAs(percent), p.Parsed, p.Discovered, p.Candidates)
+	case search.PhaseScore:
+		return fmt.Sprintf("%s scoring %d candidates", bar.ViewAs(1), p.Candidates)
+	case search.PhaseDone:
+		return fmt.Sprintf("%s %d candidates", bar.ViewAs(1), p.Candidates)
+	}
+	return fmt.Sprintf("searching files, %d found", p.Discovered)
+}
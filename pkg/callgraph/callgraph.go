@@ -0,0 +1,492 @@
+// Package callgraph builds a call graph between the candidates of all scanned files
+package callgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/bits"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dominikbraun/graph"
+	"github.com/jochil/gcs/pkg/candidate"
+	"github.com/jochil/gcs/pkg/helper"
+	"github.com/jochil/gcs/pkg/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Graph is a directed graph from the calling to the called candidates,
+// the attribute "calls" of an edge holds the number of call sites
+type Graph = graph.Graph[string, *candidate.Candidate]
+
+// ID returns a unique identifier for a candidate
+func ID(c *candidate.Candidate) string {
+	return fmt.Sprintf("%s:%d:%s", c.Path, c.StartLine, c.String())
+}
+
+// call site found in the AST of a candidate, qualifier is the receiver/package/class (eg. "fmt" for fmt.Println)
+type call struct {
+	qualifier string
+	name      string
+}
+
+// call sites of a candidate together with the name of its receiver and the imports of its file
+type sites struct {
+	receiver string
+	imports  map[string]string
+	calls    []call
+}
+
+// Builder collects the call sites of candidates while their ASTs are available, so the graph can be created
+// after the ASTs are released (see candidate.Release)
+type Builder struct {
+	mu    sync.Mutex
+	sites map[*candidate.Candidate]*sites
+}
+
+// NewBuilder creates an empty builder
+func NewBuilder() *Builder {
+	return &Builder{sites: map[*candidate.Candidate]*sites{}}
+}
+
+// Add collects the call sites of a candidate, candidates without an AST are ignored. It is safe for
+// concurrent use if the candidates of a file are added together.
+func (b *Builder) Add(c *candidate.Candidate) {
+	if c.AST == nil {
+		return
+	}
+	s := &sites{
+		receiver: receiverName(c),
+		imports:  helper.Imports(c.AST, c.Source, c.Language),
+		calls:    findCalls(c.AST.ChildByFieldName("body"), c.Source),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sites[c] = s
+}
+
+type resolver struct {
+	g      Graph
+	byName map[string]candidate.Candidates
+	// directories of the go packages
+	goDirs map[string]bool
+}
+
+// New creates the call graph for a list of candidates. Calls are resolved by the name of the function,
+// the receiver type, the class, the package and the imports of the file. Calls of imported packages outside
+// of the project are dropped, if the type of a receiver is unknown all methods with a matching name are used.
+func New(candidates candidate.Candidates) (Graph, error) {
+	b := NewBuilder()
+	for _, c := range candidates {
+		b.Add(c)
+	}
+	return b.Build(candidates)
+}
+
+// Build creates the call graph for a list of candidates (see New) based on the collected call sites
+func (b *Builder) Build(candidates candidate.Candidates) (Graph, error) {
+	r := &resolver{
+		g:      graph.New(ID, graph.Directed()),
+		byName: map[string]candidate.Candidates{},
+		goDirs: map[string]bool{},
+	}
+
+	for _, c := range candidates {
+		if c.Language == types.Go {
+			r.goDirs[filepath.Dir(c.Path)] = true
+		}
+		if err := r.g.AddVertex(c); errors.Is(err, graph.ErrVertexAlreadyExists) {
+			slog.Warn("duplicate function in call graph", "func", ID(c))
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		r.byName[c.Function.Name] = append(r.byName[c.Function.Name], c)
+	}
+
+	for _, c := range candidates {
+		s, ok := b.sites[c]
+		if !ok {
+			continue
+		}
+		counts := map[string]int{}
+		for _, call := range s.calls {
+			for _, callee := range r.resolve(c, s, call) {
+				if callee != c {
+					counts[ID(callee)]++
+				}
+			}
+		}
+
+		for _, id := range sortedKeys(counts) {
+			err := r.g.AddEdge(ID(c), id, graph.EdgeAttribute("calls", fmt.Sprint(counts[id])))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r.g, nil
+}
+
+// resolves a call to the candidates it may call
+func (r *resolver) resolve(caller *candidate.Candidate, s *sites, call call) candidate.Candidates {
+	targets := candidate.Candidates{}
+	for _, c := range r.byName[call.name] {
+		if sameLanguage(caller.Language, c.Language) {
+			targets = append(targets, c)
+		}
+	}
+
+	samePackage := func(c *candidate.Candidate) bool {
+		if caller.Language == types.Go {
+			return c.Package == caller.Package && filepath.Dir(c.Path) == filepath.Dir(caller.Path)
+		}
+		return c.Path == caller.Path
+	}
+	sameClass := func(c *candidate.Candidate) bool {
+		return c.Class != nil && caller.Class != nil && c.Class.Name == caller.Class.Name && samePackage(c)
+	}
+	isFunction := func(c *candidate.Candidate) bool {
+		return c.Class == nil
+	}
+	isMethod := func(c *candidate.Candidate) bool {
+		return c.Class != nil
+	}
+
+	switch {
+	case call.qualifier == "":
+		switch caller.Language {
+		case types.Go:
+			return targets.Filter(func(c *candidate.Candidate) bool { return isFunction(c) && samePackage(c) })
+		case types.Java:
+			// implicit this
+			return targets.Filter(sameClass)
+		}
+		// functions of other files are imported by javascript/typescript modules and c headers,
+		// functions of the same file are preferred
+		functions := targets.Filter(isFunction)
+		if local := functions.Filter(samePackage); len(local) > 0 {
+			return local
+		}
+		return functions
+
+	case call.qualifier == s.receiver || call.qualifier == "this":
+		return targets.Filter(sameClass)
+
+	case s.imports[call.qualifier] != "":
+		// calls of imported packages, classes and modules outside of the project are dropped
+		return r.resolveImport(caller, targets, s.imports[call.qualifier])
+
+	default:
+		// go: calls of functions from other packages (the package name is used as import name)
+		if pkg := targets.Filter(func(c *candidate.Candidate) bool { return isFunction(c) && c.Package == call.qualifier }); len(pkg) > 0 {
+			return pkg
+		}
+		// java: static calls (Class.method())
+		if static := targets.Filter(func(c *candidate.Candidate) bool { return isMethod(c) && c.Class.Name == call.qualifier }); len(static) > 0 {
+			return static
+		}
+		if builtin(caller.Language, call.qualifier) {
+			return nil
+		}
+		// unknown receiver type (eg. a local variable)
+		return targets.Filter(isMethod)
+	}
+}
+
+// resolves a call of an imported go package, java class or javascript module
+func (r *resolver) resolveImport(caller *candidate.Candidate, targets candidate.Candidates, path string) candidate.Candidates {
+	switch caller.Language {
+	case types.Go:
+		dirs := r.packageDirs(path)
+		return targets.Filter(func(c *candidate.Candidate) bool {
+			return c.Class == nil && dirs[filepath.Dir(c.Path)]
+		})
+	case types.Java:
+		i := strings.LastIndex(path, ".")
+		pkg, class := path[:max(i, 0)], path[i+1:]
+		return targets.Filter(func(c *candidate.Candidate) bool {
+			return c.Class != nil && c.Class.Name == class && c.Package == pkg
+		})
+	case types.JavaScript, types.TypeScript:
+		return targets.Filter(func(c *candidate.Candidate) bool {
+			return helper.ImportsFile(caller.Path, path, c.Path)
+		})
+	}
+	return targets
+}
+
+// returns the directories of the go packages matching the import path, the longest match of the trailing
+// path segments wins (eg. "example/util" matches "/src/util")
+func (r *resolver) packageDirs(path string) map[string]bool {
+	segments := strings.Split(path, "/")
+	dirs := map[string]bool{}
+	best := 0
+	for dir := range r.goDirs {
+		parts := strings.Split(filepath.ToSlash(dir), "/")
+		n := 0
+		for n < len(segments) && n < len(parts) && segments[len(segments)-1-n] == parts[len(parts)-1-n] {
+			n++
+		}
+		if n == 0 || n < best {
+			continue
+		}
+		if n > best {
+			best = n
+			dirs = map[string]bool{}
+		}
+		dirs[dir] = true
+	}
+	return dirs
+}
+
+// global objects of javascript and classes of java.lang, their methods are never part of the project
+var builtins = map[types.Language][]string{
+	types.JavaScript: {"JSON", "Math", "Object", "Array", "Promise", "Number", "String", "Date", "Reflect", "console", "process"},
+	types.Java:       {"Math", "System", "String", "Integer", "Long", "Double", "Float", "Boolean", "Character", "Objects", "Thread"},
+}
+
+func builtin(language types.Language, qualifier string) bool {
+	if language == types.TypeScript {
+		language = types.JavaScript
+	}
+	return slices.Contains(builtins[language], qualifier)
+}
+
+// returns the name of the receiver of a go method (eg. "p" for func (p *Parser) Parse())
+func receiverName(c *candidate.Candidate) string {
+	receiver := c.AST.ChildByFieldName("receiver")
+	if receiver == nil || receiver.NamedChildCount() == 0 {
+		return ""
+	}
+	name := receiver.NamedChild(0).ChildByFieldName("name")
+	if name == nil {
+		return ""
+	}
+	return name.Content(c.Source)
+}
+
+// finds all call sites inside of a node
+func findCalls(node *sitter.Node, source []byte) []call {
+	calls := []call{}
+	if node == nil || source == nil {
+		return calls
+	}
+
+	var visit func(*sitter.Node)
+	visit = func(n *sitter.Node) {
+		switch n.Type() {
+		case "call_expression":
+			callee := n.ChildByFieldName("function")
+			switch {
+			case callee == nil:
+			case callee.Type() == "identifier":
+				calls = append(calls, call{name: callee.Content(source)})
+			case callee.Type() == "selector_expression":
+				// go: pkg.Func() or obj.Method()
+				calls = appendQualified(calls, callee.ChildByFieldName("operand"), callee.ChildByFieldName("field"), source)
+			case callee.Type() == "member_expression":
+				// javascript: obj.method()
+				calls = appendQualified(calls, callee.ChildByFieldName("object"), callee.ChildByFieldName("property"), source)
+			}
+		case "method_invocation":
+			// java: method() or obj.method()
+			calls = appendQualified(calls, n.ChildByFieldName("object"), n.ChildByFieldName("name"), source)
+		}
+
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			visit(n.NamedChild(i))
+		}
+	}
+	visit(node)
+	return calls
+}
+
+func appendQualified(calls []call, qualifier, name *sitter.Node, source []byte) []call {
+	if name == nil {
+		return calls
+	}
+	c := call{name: name.Content(source)}
+	if qualifier != nil {
+		c.qualifier = qualifier.Content(source)
+	}
+	return append(calls, c)
+}
+
+// javascript and typescript are able to call each other
+func sameLanguage(a, b types.Language) bool {
+	isJS := func(l types.Language) bool {
+		return l == types.JavaScript || l == types.TypeScript
+	}
+	return a == b || (isJS(a) && isJS(b))
+}
+
+// Apply sets the fan-in, fan-out and reachability metrics of all candidates in the graph.
+// Public functions are the entry points, a function reached by many of them is a valuable target.
+func Apply(g Graph) error {
+	return ApplyContext(context.Background(), g)
+}
+
+// ApplyContext is like Apply but stops if the context is done, the error of the context is returned in this case
+func ApplyContext(ctx context.Context, g Graph) error {
+	adjacencyMap, err := g.AdjacencyMap()
+	if err != nil {
+		return err
+	}
+	predecessorMap, err := g.PredecessorMap()
+	if err != nil {
+		return err
+	}
+
+	// index the public functions for the bitsets
+	ids := sortedKeys(adjacencyMap)
+	vertices := make(map[string]*candidate.Candidate, len(ids))
+	public := map[string]int{}
+	for _, id := range ids {
+		c, err := g.Vertex(id)
+		if err != nil {
+			return err
+		}
+		vertices[id] = c
+		if c.Function.Visibility == types.VisibilityPublic {
+			public[id] = len(public)
+		}
+	}
+
+	// all functions of a cycle are reached by the same entry points, so the entry points are propagated
+	// as bitsets over the components in topological order (the callers first)
+	components := stronglyConnected(ids, adjacencyMap)
+	componentOf := map[string]int{}
+	for i, component := range components {
+		for _, id := range component {
+			componentOf[id] = i
+		}
+	}
+	reaching := make([]bitset, len(components))
+	for i, component := range components {
+		if err := helper.ContextErr(ctx); err != nil {
+			return err
+		}
+		set := reaching[i]
+		for _, id := range component {
+			if p, ok := public[id]; ok {
+				set = set.with(p)
+			}
+		}
+		// a public function is no entry point of itself
+		count := set.count()
+		for _, id := range component {
+			c := vertices[id]
+			if c.Metrics == nil {
+				slog.Warn("no metrics for call graph", "func", c.Function.Name)
+			} else {
+				_, isPublic := public[id]
+				c.Metrics.FanIn = len(predecessorMap[id])
+				c.Metrics.FanOut = len(adjacencyMap[id])
+				c.Metrics.EntryPoints = count
+				if isPublic {
+					c.Metrics.EntryPoints--
+				}
+				c.Metrics.Reachable = count > 0
+			}
+			for callee := range adjacencyMap[id] {
+				if j := componentOf[callee]; j != i {
+					reaching[j] = reaching[j].union(set)
+				}
+			}
+		}
+		// not needed by the following components anymore
+		reaching[i] = nil
+	}
+	return nil
+}
+
+// returns the strongly connected components (Tarjan) in topological order, the callers before the callees
+func stronglyConnected(ids []string, adjacencyMap map[string]map[string]graph.Edge[string]) [][]string {
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	stack := []string{}
+	components := [][]string{}
+
+	var visit func(v string)
+	visit = func(v string) {
+		index[v] = len(index)
+		low[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+		for w := range adjacencyMap[v] {
+			if _, ok := index[w]; !ok {
+				visit(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+		if low[v] == index[v] {
+			component := []string{}
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+	for _, id := range ids {
+		if _, ok := index[id]; !ok {
+			visit(id)
+		}
+	}
+	// tarjan finds the callees first
+	slices.Reverse(components)
+	return components
+}
+
+// set of public functions, nil is the empty set
+type bitset []uint64
+
+func (b bitset) with(i int) bitset {
+	for len(b) <= i/64 {
+		b = append(b, 0)
+	}
+	b[i/64] |= 1 << (i % 64)
+	return b
+}
+
+func (b bitset) union(other bitset) bitset {
+	for len(b) < len(other) {
+		b = append(b, 0)
+	}
+	for i, word := range other {
+		b[i] |= word
+	}
+	return b
+}
+
+func (b bitset) count() int {
+	n := 0
+	for _, word := range b {
+		n += bits.OnesCount64(word)
+	}
+	return n
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
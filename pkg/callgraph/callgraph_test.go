@@ -0,0 +1,240 @@
+package callgraph_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/dominikbraun/graph"
+	"github.com/jochil/gcs/pkg/callgraph"
+	"github.com/jochil/gcs/pkg/candidate"
+	"github.com/jochil/gcs/pkg/metrics"
+	"github.com/jochil/gcs/pkg/search"
+	"github.com/jochil/gcs/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// returns the call graph with the edges as "caller -> callee" using the qualified function names
+func edges(t *testing.T, g callgraph.Graph) []string {
+	t.Helper()
+	adjacencyMap, err := g.AdjacencyMap()
+	require.NoError(t, err)
+
+	name := func(id string) string {
+		c, err := g.Vertex(id)
+		require.NoError(t, err)
+		if c.Class != nil {
+			return c.Class.Name + "." + c.Function.Name
+		}
+		return c.Function.Name
+	}
+
+	result := []string{}
+	for source, targets := range adjacencyMap {
+		for target := range targets {
+			result = append(result, name(source)+" -> "+name(target))
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func byName(candidates candidate.Candidates, name string) *candidate.Candidate {
+	for _, c := range candidates {
+		if c.Function.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	testCases := map[string]struct {
+		path  string
+		edges []string
+	}{
+		"go": {
+			path: "testdata/golang",
+			edges: []string{
+				"Check -> helper",
+				"Parser.Parse -> Parser.tokenize",
+				"Parser.Parse -> validate",
+				"Parser.tokenize -> validate",
+				"Run -> Check",
+				"Run -> Parser.Parse",
+				"Serve -> Check",
+				"Serve -> validate",
+			},
+		},
+		"java": {
+			path: "testdata/java",
+			edges: []string{
+				"Calc.add -> Calc.check",
+				"Calc.check -> Util.abs",
+			},
+		},
+		"javascript": {
+			path: "testdata/javascript",
+			edges: []string{
+				"load -> parse",
+				"parse -> helper",
+				"run -> helper",
+				"run -> parse",
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			candidates, err := search.Search([]string{tc.path})
+			require.NoError(t, err)
+			g, err := callgraph.New(candidates)
+			require.NoError(t, err)
+			assert.Equal(t, tc.edges, edges(t, g))
+		})
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	candidates, err := search.Search([]string{"testdata/golang"})
+	require.NoError(t, err)
+	expected, err := callgraph.New(candidates)
+	require.NoError(t, err)
+
+	// the call sites are collected before the ASTs are released
+	b := callgraph.NewBuilder()
+	for _, c := range candidates {
+		b.Add(c)
+		c.Release()
+	}
+	g, err := b.Build(candidates)
+	require.NoError(t, err)
+	assert.Equal(t, edges(t, expected), edges(t, g))
+
+	// without the call sites there are no edges
+	g, err = callgraph.New(candidates)
+	require.NoError(t, err)
+	assert.Empty(t, edges(t, g))
+}
+
+func TestApply(t *testing.T) {
+	candidates, err := search.Search([]string{"testdata/golang"})
+	require.NoError(t, err)
+	g, err := callgraph.New(candidates)
+	require.NoError(t, err)
+	require.NoError(t, callgraph.Apply(g))
+
+	testCases := map[string]struct {
+		fanIn       int
+		fanOut      int
+		entryPoints int
+		reachable   bool
+	}{
+		// Run, Serve, Parse and Check are public
+		"validate": {fanIn: 3, fanOut: 0, entryPoints: 3, reachable: true},
+		"tokenize": {fanIn: 1, fanOut: 1, entryPoints: 2, reachable: true},
+		"Parse":    {fanIn: 1, fanOut: 2, entryPoints: 1, reachable: true},
+		"Check":    {fanIn: 2, fanOut: 1, entryPoints: 2, reachable: true},
+		"helper":   {fanIn: 1, fanOut: 0, entryPoints: 3, reachable: true},
+		"Run":      {fanIn: 0, fanOut: 2, entryPoints: 0, reachable: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			c := byName(candidates, name)
+			require.NotNil(t, c)
+			assert.Equal(t, tc.fanIn, c.Metrics.FanIn, "wrong fan-in")
+			assert.Equal(t, tc.fanOut, c.Metrics.FanOut, "wrong fan-out")
+			assert.Equal(t, tc.entryPoints, c.Metrics.EntryPoints, "wrong entry points")
+			assert.Equal(t, tc.reachable, c.Metrics.Reachable)
+		})
+	}
+}
+
+func TestApply_Cycles(t *testing.T) {
+	// A and B are public and call each other, both reach C and D
+	newCandidate := func(name, visibility string) *candidate.Candidate {
+		return &candidate.Candidate{
+			Path:     "a.go",
+			Function: &candidate.Function{Name: name, Visibility: visibility},
+			Metrics:  &metrics.Metrics{},
+		}
+	}
+	a := newCandidate("A", types.VisibilityPublic)
+	b := newCandidate("B", types.VisibilityPublic)
+	c := newCandidate("c", types.VisibilityPrivate)
+	d := newCandidate("d", types.VisibilityPrivate)
+	e := newCandidate("e", types.VisibilityPrivate)
+
+	g := graph.New(callgraph.ID, graph.Directed())
+	for _, v := range []*candidate.Candidate{a, b, c, d, e} {
+		require.NoError(t, g.AddVertex(v))
+	}
+	for _, edge := range [][2]*candidate.Candidate{{a, b}, {b, a}, {b, c}, {c, d}, {d, c}, {e, d}} {
+		require.NoError(t, g.AddEdge(callgraph.ID(edge[0]), callgraph.ID(edge[1])))
+	}
+	require.NoError(t, callgraph.Apply(g))
+
+	testCases := map[*candidate.Candidate]struct {
+		entryPoints int
+		reachable   bool
+	}{
+		a: {entryPoints: 1, reachable: true},
+		b: {entryPoints: 1, reachable: true},
+		c: {entryPoints: 2, reachable: true},
+		d: {entryPoints: 2, reachable: true},
+		e: {entryPoints: 0, reachable: false},
+	}
+	for c, tc := range testCases {
+		assert.Equal(t, tc.entryPoints, c.Metrics.EntryPoints, "wrong entry points for %s", c.Function.Name)
+		assert.Equal(t, tc.reachable, c.Metrics.Reachable, "wrong reachability for %s", c.Function.Name)
+	}
+	assert.Equal(t, 2, c.Metrics.FanIn)
+	assert.Equal(t, 1, c.Metrics.FanOut)
+}
+
+func TestApplyContext_Canceled(t *testing.T) {
+	candidates, err := search.Search([]string{"testdata/golang"})
+	require.NoError(t, err)
+	g, err := callgraph.New(candidates)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, callgraph.ApplyContext(ctx, g), context.Canceled)
+}
+
+func TestExport(t *testing.T) {
+	candidates, err := search.Search([]string{"testdata/java"})
+	require.NoError(t, err)
+	g, err := callgraph.New(candidates)
+	require.NoError(t, err)
+	require.NoError(t, callgraph.Apply(g))
+
+	add := callgraph.ID(byName(candidates, "add"))
+	check := callgraph.ID(byName(candidates, "check"))
+
+	dot := &bytes.Buffer{}
+	require.NoError(t, callgraph.Export(g, dot, callgraph.FormatDOT))
+	assert.Contains(t, dot.String(), "strict digraph {")
+	assert.Contains(t, dot.String(), `label="Calc.add", shape=box`)
+	assert.Contains(t, dot.String(), `label="Calc.check", shape=ellipse`)
+	assert.Contains(t, dot.String(), `"`+add+`" -> "`+check+`" [label="2"];`)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, callgraph.Export(g, out, callgraph.FormatJSON))
+	exported := struct {
+		Nodes []map[string]any  `json:"nodes"`
+		Edges []*callgraph.Edge `json:"edges"`
+	}{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &exported))
+	assert.Len(t, exported.Nodes, 3)
+	assert.Equal(t, "Java", exported.Nodes[0]["language"])
+	assert.Contains(t, exported.Edges, &callgraph.Edge{Source: add, Target: check, Calls: 2})
+
+	_, err = callgraph.ParseFormat("svg")
+	require.Error(t, err)
+}
@@ -0,0 +1,141 @@
+package callgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jochil/gcs/pkg/types"
+)
+
+// Format describes an output format for exporting a call graph
+type Format string
+
+const (
+	FormatDOT  Format = "dot"
+	FormatJSON Format = "json"
+)
+
+// Formats lists all supported export formats
+var Formats = []Format{FormatDOT, FormatJSON}
+
+// ParseFormat validates a format given as string (eg. by a command line flag)
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(s))
+	if !slices.Contains(Formats, f) {
+		return "", fmt.Errorf("unsupported call graph format: %s", s)
+	}
+	return f, nil
+}
+
+// Node is the exported representation of a function
+type Node struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Class       string         `json:"class,omitempty"`
+	Package     string         `json:"package,omitempty"`
+	Path        string         `json:"path"`
+	Language    types.Language `json:"language"`
+	Visibility  string         `json:"visibility"`
+	FanIn       int            `json:"fan_in"`
+	FanOut      int            `json:"fan_out"`
+	EntryPoints int            `json:"entry_points"`
+	Reachable   bool           `json:"reachable"`
+}
+
+// Edge is the exported representation of the calls from one function to another
+type Edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Calls  int    `json:"calls"`
+}
+
+// Export writes the call graph in the given format, nodes and edges are sorted by their ids
+func Export(g Graph, w io.Writer, format Format) error {
+	nodes, edges, err := export(g)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatDOT:
+		return writeDOT(w, nodes, edges)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Nodes []*Node `json:"nodes"`
+			Edges []*Edge `json:"edges"`
+		}{nodes, edges})
+	}
+	return fmt.Errorf("unsupported call graph format: %s", format)
+}
+
+func export(g Graph) ([]*Node, []*Edge, error) {
+	adjacencyMap, err := g.AdjacencyMap()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes := []*Node{}
+	edges := []*Edge{}
+	for _, id := range sortedKeys(adjacencyMap) {
+		c, err := g.Vertex(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		node := &Node{
+			ID:         id,
+			Name:       c.Function.Name,
+			Package:    c.Package,
+			Path:       c.Path,
+			Language:   c.Language,
+			Visibility: c.Function.Visibility,
+		}
+		if c.Class != nil {
+			node.Class = c.Class.Name
+		}
+		if c.Metrics != nil {
+			node.FanIn = c.Metrics.FanIn
+			node.FanOut = c.Metrics.FanOut
+			node.EntryPoints = c.Metrics.EntryPoints
+			node.Reachable = c.Metrics.Reachable
+		}
+		nodes = append(nodes, node)
+
+		for _, target := range sortedKeys(adjacencyMap[id]) {
+			calls, _ := strconv.Atoi(adjacencyMap[id][target].Properties.Attributes["calls"])
+			edges = append(edges, &Edge{Source: id, Target: target, Calls: calls})
+		}
+	}
+	return nodes, edges, nil
+}
+
+func writeDOT(w io.Writer, nodes []*Node, edges []*Edge) error {
+	sb := &strings.Builder{}
+	sb.WriteString("strict digraph {\n")
+	for _, n := range nodes {
+		label := n.Name
+		if n.Class != "" {
+			label = n.Class + "." + label
+		}
+		if n.Package != "" {
+			label = n.Package + "." + label
+		}
+		shape := "ellipse"
+		if n.Visibility == types.VisibilityPublic {
+			shape = "box"
+		}
+		fmt.Fprintf(sb, "\t%s [label=%s, shape=%s];\n", strconv.Quote(n.ID), strconv.Quote(label), shape)
+	}
+	for _, e := range edges {
+		fmt.Fprintf(sb, "\t%s -> %s [label=%s];\n", strconv.Quote(e.Source), strconv.Quote(e.Target), strconv.Quote(strconv.Itoa(e.Calls)))
+	}
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net/url"
+
+	"example/util"
+)
+
+type Parser struct{}
+
+func Run(input string) {
+	p := &Parser{}
+	p.Parse(input)
+	util.Check(input)
+}
+
+func Serve(input string) {
+	// not Parser.Parse
+	url.Parse(input)
+	validate(input)
+	util.Check(input)
+}
+
+func (p *Parser) Parse(input string) {
+	p.tokenize(input)
+	validate(input)
+}
+
+func (p *Parser) tokenize(input string) {
+	validate(input)
+	p.tokenize(input[1:])
+}
+
+func validate(input string) {
+	println(input)
+}
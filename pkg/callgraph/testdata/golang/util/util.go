@@ -0,0 +1,9 @@
+package util
+
+func Check(input string) bool {
+	return helper(input)
+}
+
+func helper(input string) bool {
+	return input != ""
+}
@@ -0,0 +1,15 @@
+class Calc {
+    public int add(int a, int b) {
+        return check(a) + check(b) + Math.abs(b);
+    }
+
+    private int check(int a) {
+        return Util.abs(a);
+    }
+}
+
+class Util {
+    public static int abs(int a) {
+        return a < 0 ? -a : a;
+    }
+}
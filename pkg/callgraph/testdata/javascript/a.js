@@ -0,0 +1,12 @@
+function run(x) {
+  return parse(x) + helper(x);
+}
+
+function parse(x) {
+  return helper(x);
+}
+
+function helper(x) {
+  // not parse
+  return JSON.parse(x);
+}
@@ -0,0 +1,13 @@
+import * as a from "./a";
+import fs from "fs";
+
+function load(p) {
+  // not Store.readFile
+  return a.parse(fs.readFile(p));
+}
+
+class Store {
+  readFile(p) {
+    return p;
+  }
+}
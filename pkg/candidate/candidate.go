@@ -1,16 +1,25 @@
 package candidate
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
-	"github.com/dominikbraun/graph"
-	"github.com/dominikbraun/graph/draw"
 	"github.com/jochil/gcs/pkg/cfg"
+	"github.com/jochil/gcs/pkg/churn"
+	"github.com/jochil/gcs/pkg/coverage"
+	"github.com/jochil/gcs/pkg/dataflow"
+	"github.com/jochil/gcs/pkg/helper"
 	"github.com/jochil/gcs/pkg/metrics"
+	"github.com/jochil/gcs/pkg/sinks"
+	"github.com/jochil/gcs/pkg/testcase"
 	"github.com/jochil/gcs/pkg/types"
 	sitter "github.com/smacker/go-tree-sitter"
 )
@@ -48,6 +57,12 @@ type Function struct {
 	ReturnValues []*Parameter `json:"return_values"`
 	Visibility   string       `json:"visibility"`
 	Static       bool         `json:"static"`
+	// Throws are the exceptions declared by a java method
+	Throws []string `json:"throws,omitempty"`
+}
+
+func (f *Function) ReturnTypes() []string {
+	return Parameters(f.ReturnValues).Types()
 }
 
 func (f *Function) String() string {
@@ -80,16 +95,24 @@ func (c *Class) String() string {
 }
 
 type Candidate struct {
-	Path             string                `json:"path"`
-	Function         *Function             `json:"function"`
-	Class            *Class                `json:"class,omitempty"`
-	Package          string                `json:"package,omitempty"`
-	ControlFlowGraph graph.Graph[int, int] `json:"-"`
-	Score            float64               `json:"score"`
-	Metrics          *metrics.Metrics      `json:"metrics"`
-	Code             string                `json:"code"`
-	AST              *sitter.Node          `json:"-"`
-	Language         types.Language        `json:"language"`
+	Path             string           `json:"path"`
+	Function         *Function        `json:"function"`
+	Class            *Class           `json:"class,omitempty"`
+	Package          string           `json:"package,omitempty"`
+	ControlFlowGraph cfg.Graph        `json:"-"`
+	Score            float64          `json:"score"`
+	Metrics          *metrics.Metrics `json:"metrics"`
+	Code             string           `json:"code"`
+	AST              *sitter.Node     `json:"-"`
+	Source           []byte           `json:"-"`
+	Language         types.Language   `json:"language"`
+	// StartLine and EndLine are the 1-based lines of the function
+	StartLine int `json:"start_line"`
+	EndLine   int `json:"end_line"`
+	// Tests are the names of existing unit tests and fuzz targets calling the function
+	Tests []string `json:"tests,omitempty"`
+	// Contributions explain the score by the share of every metric
+	Contributions []*Contribution `json:"contributions,omitempty"`
 }
 
 func (c *Candidate) String() string {
@@ -109,12 +132,25 @@ func (c *Candidate) CalculateMetrics() {
 
 	c.Metrics.FuzzFriendlyName = metrics.HasFuzzFriendlyName(c.Function.Name)
 	c.Metrics.PrimitiveParametersOnly = metrics.HasPrimitiveParametersOnly(c.Function.Parameters.Types(), c.Language)
+	c.Metrics.ParameterCount = len(c.Function.Parameters)
+	c.Metrics.RawInputParameters = metrics.CountRawInputParameters(c.Function.Parameters.Types(), c.Language)
+	c.Metrics.ReturnsError = metrics.ReturnsError(c.Function.ReturnTypes(), c.Function.Throws, c.Language)
+	c.Metrics.ReturnsValue = len(c.Function.ReturnValues) > 0
+	// javascript (and typescript without annotations) has no declared return types
+	if !c.Metrics.ReturnsValue && (c.Language == types.JavaScript || c.Language == types.TypeScript) && c.AST != nil {
+		c.Metrics.ReturnsValue = metrics.HasReturnValue(c.AST.ChildByFieldName("body"))
+	}
 
 	// calculate cfg + metrics for candidate
 	if c.AST != nil {
 		if body := c.AST.ChildByFieldName("body"); body != nil {
-			c.ControlFlowGraph = cfg.Create(body)
-			c.Metrics.LinesOfCode = metrics.CountLines(c.Code)
+			c.ControlFlowGraph = cfg.Create(body, c.Source)
+			lines := metrics.CountLines(c.AST, c.Source)
+			c.Metrics.LinesOfCode = lines.Physical
+			c.Metrics.LogicalLinesOfCode = lines.Logical
+			c.Metrics.CommentLines = lines.Comment
+			c.Metrics.BlankLines = lines.Blank
+			c.Metrics.CognitiveComplexity = metrics.CalcCognitiveComplexity(c.AST, c.Function.Name, c.Source)
 		}
 	}
 
@@ -125,22 +161,228 @@ func (c *Candidate) CalculateMetrics() {
 			slog.Warn("unable to calc cyclomatic complexity", "func", c.Function.Name)
 		}
 		c.Metrics.CyclomaticComplexity = cc
+
+		halstead := metrics.CountHalstead(c.AST, c.Source)
+		c.Metrics.HalsteadVolume = halstead.Volume()
+		c.Metrics.HalsteadDifficulty = halstead.Difficulty()
+		c.Metrics.HalsteadEffort = halstead.Effort()
+		c.Metrics.MaintainabilityIndex = metrics.CalcMaintainabilityIndex(c.Metrics.HalsteadVolume, cc, c.Metrics.LinesOfCode)
+
+		analysis, err := cfg.Analyze(c.ControlFlowGraph)
+		if err != nil {
+			slog.Warn("unable to analyze control flow graph", "func", c.Function.Name, "error", err)
+		} else {
+			c.Metrics.Loops = len(analysis.Loops)
+			c.Metrics.MaxLoopDepth = analysis.MaxLoopDepth()
+			c.Metrics.UnreachableBlocks = len(analysis.Unreachable)
+		}
+
+		npath, err := cfg.NPath(c.ControlFlowGraph)
+		if err != nil {
+			npath = -1
+			slog.Warn("unable to calc npath complexity", "func", c.Function.Name)
+		}
+		c.Metrics.NPathComplexity = npath
+	}
+
+}
+
+// Release drops the AST and the source code once all metrics depending on them are calculated, so the
+// tree-sitter tree of the file can be freed while the candidate is kept
+func (c *Candidate) Release() {
+	c.AST = nil
+	c.Source = nil
+}
+
+// CalculateSinks counts the calls of sinks (see sinks.Default) inside of the function
+func (c *Candidate) CalculateSinks(catalog sinks.Catalog) {
+	if c.Metrics == nil {
+		c.Metrics = &metrics.Metrics{}
 	}
+	c.Metrics.Sinks = catalog.Count(c.AST, c.Source, c.Language)
+	c.Metrics.SinkCalls = 0
+	for _, count := range c.Metrics.Sinks {
+		c.Metrics.SinkCalls += count
+	}
+}
 
+// CalculateDataFlow tracks the parameters through the function and counts the branches, loops, index expressions
+// and sink calls depending on them
+func (c *Candidate) CalculateDataFlow(catalog sinks.Catalog) {
+	if c.Metrics == nil {
+		c.Metrics = &metrics.Metrics{}
+	}
+	result := dataflow.Analyze(c.AST, c.Source, c.Language, catalog)
+	c.Metrics.ParamBranches = result.ParamBranches
+	c.Metrics.TaintedLoops = result.TaintedLoops
+	c.Metrics.TaintedIndexes = result.TaintedIndexes
+	c.Metrics.TaintedSinks = result.TaintedSinks
 }
 
-func (c *Candidate) SaveGraph() {
-	filename := fmt.Sprintf("%s_%s_%s.gv", c.Package, c.Class, c.Function.Name)
-	file, _ := os.Create(filepath.Join("..", "..", ".draw", filename))
-	err := draw.DOT(c.ControlFlowGraph, file)
+// CalculateChurn attributes the git history of the candidate file to the lines of the function
+func (c *Candidate) CalculateChurn(a *churn.Analyzer) error {
+	if c.AST == nil {
+		return fmt.Errorf("no AST for %s", c.Function.Name)
+	}
+	if c.Metrics == nil {
+		c.Metrics = &metrics.Metrics{}
+	}
+
+	start := int(c.AST.StartPoint().Row) + 1
+	end := int(c.AST.EndPoint().Row) + 1
+	ch, err := a.Churn(c.Path, start, end)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	c.Metrics.ChurnCommits = ch.Commits
+	c.Metrics.ChurnLinesChanged = ch.LinesChanged
+	c.Metrics.ChurnAuthors = len(ch.Authors)
+	c.Metrics.LastModified = ch.LastModified
+	return nil
+}
+
+// CalculateCoverage sets the line and branch coverage of the function based on the imported coverage reports,
+// functions in files missing in the reports are handled as not covered at all
+func (c *Candidate) CalculateCoverage(p *coverage.Profile) error {
+	if c.AST == nil {
+		return fmt.Errorf("no AST for %s", c.Function.Name)
+	}
+	if c.Metrics == nil {
+		c.Metrics = &metrics.Metrics{}
+	}
+
+	start := int(c.AST.StartPoint().Row) + 1
+	end := int(c.AST.EndPoint().Row) + 1
+	cov, ok := p.Coverage(c.Path, start, end)
+	c.Metrics.HasCoverage = ok
+	if !ok {
+		c.Metrics.LineCoverage = 0
+		c.Metrics.BranchCoverage = 0
+		return nil
+	}
+	c.Metrics.LineCoverage = cov.LineCoverage()
+	c.Metrics.BranchCoverage = cov.BranchCoverage()
+	return nil
+}
+
+// LinkTests links the tests calling a function with the same name as the candidate. Go tests have to be
+// in the same package (directory), java tests in the same package or import the class of the candidate and
+// javascript/typescript tests have to import the module of the candidate (of both languages).
+func (c *Candidate) LinkTests(tests []*testcase.Test) {
+	if c.Metrics == nil {
+		c.Metrics = &metrics.Metrics{}
+	}
+	c.Tests = nil
+	for _, t := range tests {
+		if !slices.Contains(t.Calls, c.Function.Name) || !c.compatible(t) {
+			continue
+		}
+		c.Tests = append(c.Tests, t.Name)
+		switch t.Kind {
+		case testcase.Unit:
+			c.Metrics.HasUnitTest = true
+		case testcase.Fuzz:
+			c.Metrics.HasFuzzTest = true
+		}
+	}
+}
+
+// checks if a test is able to call the function of the candidate
+func (c *Candidate) compatible(t *testcase.Test) bool {
+	isJS := func(l types.Language) bool {
+		return l == types.JavaScript || l == types.TypeScript
+	}
+	switch {
+	case c.Language == types.Go && t.Language == types.Go:
+		return filepath.Dir(c.Path) == filepath.Dir(t.Path)
+	case c.Language == types.Java && t.Language == types.Java:
+		if c.Package == t.Package {
+			return true
+		}
+		return c.Class != nil && t.Imports[c.Class.Name] == c.Package+"."+c.Class.Name
+	case isJS(c.Language) && isJS(t.Language):
+		for _, specifier := range t.Imports {
+			if helper.ImportsFile(t.Path, specifier, c.Path) {
+				return true
+			}
+		}
+		return false
+	}
+	return c.Language == t.Language
+}
+
+// Paths enumerates up to limit acyclic execution paths through the function, a limit <= 0 returns all paths
+func (c *Candidate) Paths(limit int) ([]*cfg.Path, error) {
+	if c.ControlFlowGraph == nil {
+		return nil, fmt.Errorf("no control flow graph for %s", c.Function.Name)
+	}
+	return cfg.Paths(c.ControlFlowGraph, limit)
+}
+
+// GraphFilename returns the filename used for saving the control flow graph in the given format, the source file
+// and the line of the function keep overloaded and equally named functions apart
+func (c *Candidate) GraphFilename(format cfg.Format) string {
+	parts := []string{}
+	if c.Package != "" {
+		parts = append(parts, c.Package)
+	}
+	if c.Class != nil {
+		parts = append(parts, c.Class.Name)
+	}
+	file := filepath.Base(c.Path)
+	parts = append(parts, c.Function.Name, strings.TrimSuffix(file, filepath.Ext(file)), strconv.Itoa(c.StartLine))
+	return strings.Join(parts, "_") + format.Ext()
+}
+
+// WriteGraph writes the control flow graph in the given format
+func (c *Candidate) WriteGraph(w io.Writer, format cfg.Format) error {
+	if c.ControlFlowGraph == nil {
+		return fmt.Errorf("no control flow graph for %s", c.Function.Name)
+	}
+	return cfg.Export(c.ControlFlowGraph, w, format)
+}
+
+// WriteGraphs writes the control flow graphs of all candidates in the given format, multiple graphs are only
+// supported as JSON and written as one array
+func (candidates Candidates) WriteGraphs(w io.Writer, format cfg.Format) error {
+	if len(candidates) == 1 {
+		return candidates[0].WriteGraph(w, format)
+	}
+	if format != cfg.FormatJSON {
+		return fmt.Errorf("%d functions found, multiple graphs can only be written as %s", len(candidates), cfg.FormatJSON)
 	}
-	slog.Info("saved cfg", "function", c, "file", file.Name())
-	cmd := exec.Command("dot", "-Tsvg", "-O", file.Name())
-	slog.Debug("run command", "cmd", cmd.String())
-	err = cmd.Run()
+
+	type functionGraph struct {
+		Function  string          `json:"function"`
+		Path      string          `json:"path"`
+		StartLine int             `json:"start_line"`
+		Graph     json.RawMessage `json:"graph"`
+	}
+	graphs := []functionGraph{}
+	for _, c := range candidates {
+		buf := &bytes.Buffer{}
+		if err := c.WriteGraph(buf, format); err != nil {
+			return err
+		}
+		graphs = append(graphs, functionGraph{Function: c.String(), Path: c.Path, StartLine: c.StartLine, Graph: buf.Bytes()})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(graphs)
+}
+
+// SaveGraph saves the control flow graph in the given directory and returns the path of the created file
+func (c *Candidate) SaveGraph(dir string, format cfg.Format) (string, error) {
+	path := filepath.Join(dir, c.GraphFilename(format))
+	file, err := os.Create(path)
 	if err != nil {
-		slog.Error("unable to generate svg from gv file", "error", err.Error(), "file", file.Name())
+		return "", err
+	}
+	defer file.Close()
+
+	if err := c.WriteGraph(file, format); err != nil {
+		return "", err
 	}
+	slog.Info("saved cfg", "function", c, "file", path)
+	return path, nil
 }
@@ -1,38 +1,145 @@
 package candidate_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
 	"testing"
 
-	"github.com/jochil/gcs/pkg/helper"
+	"github.com/jochil/gcs/pkg/candidate"
+	"github.com/jochil/gcs/pkg/cfg"
 	"github.com/jochil/gcs/pkg/parser"
+	"github.com/jochil/gcs/pkg/testcase"
+	"github.com/jochil/gcs/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestCalculateMetrics(t *testing.T) {
 	tests := map[string]struct {
-		path  string
-		cc    int
-		lines int
+		path        string
+		cc          int
+		lines       int
+		loops       int
+		maxDepth    int
+		unreachable int
 	}{
 		"no_control":        {path: "../cfg/testdata/cyclo/golang/a.go", cc: 1, lines: 4},
 		"simple_if":         {path: "../cfg/testdata/cyclo/golang/b.go", cc: 2, lines: 6},
 		"else_if":           {path: "../cfg/testdata/cyclo/golang/c.go", cc: 4, lines: 12},
 		"switch_no_default": {path: "../cfg/testdata/cyclo/golang/d.go", cc: 2, lines: 6},
 		"switch_default":    {path: "../cfg/testdata/cyclo/golang/e.go", cc: 3, lines: 10},
-		"simple_for":        {path: "../cfg/testdata/cyclo/golang/f.go", cc: 2, lines: 5},
-		"java_while":        {path: "../cfg/testdata/cyclo/java/While.java", cc: 2, lines: 6},
-		"java_do":           {path: "../cfg/testdata/cyclo/java/Do.java", cc: 2, lines: 6},
+		"simple_for":        {path: "../cfg/testdata/cyclo/golang/f.go", cc: 2, lines: 5, loops: 1, maxDepth: 1},
+		"basic_blocks":      {path: "../cfg/testdata/cyclo/golang/g.go", cc: 2, lines: 10},
+		"nested_for":        {path: "../cfg/testdata/cyclo/golang/h.go", cc: 3, lines: 9, loops: 2, maxDepth: 2},
+		"empty_branches":    {path: "../cfg/testdata/cyclo/golang/i.go", cc: 4, lines: 8},
+		"jumps":             {path: "../cfg/testdata/cyclo/golang/k.go", cc: 5, lines: 19, loops: 1, maxDepth: 1, unreachable: 3},
+		"java_while":        {path: "../cfg/testdata/cyclo/java/While.java", cc: 2, lines: 6, loops: 1, maxDepth: 1},
+		"java_do":           {path: "../cfg/testdata/cyclo/java/Do.java", cc: 2, lines: 6, loops: 1, maxDepth: 1},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			candidates := parser.NewParser(helper.GuessLanguage(tc.path)).Parse()
+			candidates, err := parser.ParseFile(tc.path)
+			require.NoError(t, err)
 			candidates.CalcScore()
 			require.Len(t, candidates, 1)
 			c := candidates[0]
 			assert.Equal(t, tc.cc, c.Metrics.CyclomaticComplexity, "wrong cyclic complexity for function")
 			assert.Equal(t, tc.lines, c.Metrics.LinesOfCode)
+			assert.Equal(t, tc.loops, c.Metrics.Loops, "wrong amount of loops")
+			assert.Equal(t, tc.maxDepth, c.Metrics.MaxLoopDepth, "wrong loop depth")
+			assert.Equal(t, tc.unreachable, c.Metrics.UnreachableBlocks, "wrong amount of unreachable blocks")
 		})
 	}
 }
+
+func TestLinkTests(t *testing.T) {
+	javaTests, err := testcase.Parse("../testcase/testdata/ParserTest.java", types.Java)
+	require.NoError(t, err)
+	jsTests, err := testcase.Parse("../testcase/testdata/parser.test.js", types.JavaScript)
+	require.NoError(t, err)
+	tests := append(javaTests, jsTests...)
+
+	java := func(pkg, class, name string) *candidate.Candidate {
+		return &candidate.Candidate{
+			Function: &candidate.Function{Name: name},
+			Class:    &candidate.Class{Name: class},
+			Package:  pkg,
+			Language: types.Java,
+		}
+	}
+	js := func(path, name string) *candidate.Candidate {
+		return &candidate.Candidate{
+			Function: &candidate.Function{Name: name},
+			Path:     path,
+			Language: types.JavaScript,
+		}
+	}
+
+	testCases := map[string]struct {
+		candidate *candidate.Candidate
+		expected  []string
+	}{
+		"java_same_package":     {candidate: java("com.example", "Parser", "parse"), expected: []string{"parse", "fuzzParse"}},
+		"java_imported_class":   {candidate: java("com.example.codec", "Encoder", "encode"), expected: []string{"encode"}},
+		"java_other_package":    {candidate: java("com.other", "Parser", "parse")},
+		"java_not_imported":     {candidate: java("com.other", "Encoder", "encode")},
+		"js_imported_module":    {candidate: js("../testcase/testdata/parser.js", "parse"), expected: []string{"parser parses numbers", "fuzz parse"}},
+		"js_imported_index":     {candidate: js("../testcase/testdata/parser/index.ts", "encode"), expected: []string{"parser encodes"}},
+		"js_not_imported":       {candidate: js("../testcase/testdata/lexer.js", "parse")},
+		"js_other_directory":    {candidate: js("../testcase/parser.js", "parse")},
+		"java_no_call_matching": {candidate: java("com.example", "Parser", "validate")},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tc.candidate.LinkTests(tests)
+			assert.ElementsMatch(t, tc.expected, tc.candidate.Tests)
+			assert.Equal(t, len(tc.expected) > 0, tc.candidate.Metrics.HasUnitTest || tc.candidate.Metrics.HasFuzzTest)
+		})
+	}
+}
+
+func TestGraphs_Overloading(t *testing.T) {
+	candidates, err := parser.ParseFile("../parser/testdata/java/overloading.java")
+	require.NoError(t, err)
+	candidates.CalcScore()
+	require.Len(t, candidates, 3)
+
+	// every overloaded function gets its own file
+	dir := t.TempDir()
+	files := map[string]bool{}
+	for _, c := range candidates {
+		path, err := c.SaveGraph(dir, cfg.FormatDOT)
+		require.NoError(t, err)
+		files[filepath.Base(path)] = true
+	}
+	assert.Equal(t, map[string]bool{
+		"org.example_Foo_A_overloading_4.gv":  true,
+		"org.example_Foo_A_overloading_7.gv":  true,
+		"org.example_Foo_A_overloading_10.gv": true,
+	}, files)
+
+	// multiple graphs are written as one JSON array
+	out := &bytes.Buffer{}
+	require.NoError(t, candidates.WriteGraphs(out, cfg.FormatJSON))
+	graphs := []struct {
+		Function  string `json:"function"`
+		StartLine int    `json:"start_line"`
+		Graph     struct {
+			Nodes []any `json:"nodes"`
+		} `json:"graph"`
+	}{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &graphs))
+	lines := []int{}
+	for _, g := range graphs {
+		lines = append(lines, g.StartLine)
+		assert.Contains(t, g.Function, "org.example.Foo:A")
+		assert.NotEmpty(t, g.Graph.Nodes)
+	}
+	assert.ElementsMatch(t, []int{4, 7, 10}, lines)
+
+	require.Error(t, candidates.WriteGraphs(&bytes.Buffer{}, cfg.FormatDOT))
+	require.NoError(t, candidates[:1].WriteGraphs(&bytes.Buffer{}, cfg.FormatDOT))
+}
@@ -1,57 +1,321 @@
 package candidate
 
-import "log/slog"
+import (
+	"log/slog"
+	"maps"
+	"slices"
+
+	"github.com/jochil/gcs/pkg/churn"
+	"github.com/jochil/gcs/pkg/config"
+	"github.com/jochil/gcs/pkg/coverage"
+	"github.com/jochil/gcs/pkg/expr"
+	"github.com/jochil/gcs/pkg/helper"
+	"github.com/jochil/gcs/pkg/metrics"
+	"github.com/jochil/gcs/pkg/normalize"
+	"github.com/jochil/gcs/pkg/sinks"
+	"github.com/jochil/gcs/pkg/testcase"
+)
 
 type Candidates []*Candidate
 
+type ScoreOptions struct {
+	// Churn enables the git churn metrics, candidates outside of a repository get no churn
+	Churn *churn.Analyzer
+	// Coverage favors complex functions with a low test coverage
+	Coverage *coverage.Profile
+	// Tests are existing unit tests and fuzz targets, tested functions get a lower score
+	Tests []*testcase.Test
+	// Sinks is the catalog of interesting callees, sinks.Default is used if not set
+	Sinks sinks.Catalog
+	// Scoring contains the weights of the metrics, the default profile is used if not set
+	Scoring *config.Scoring
+	// Jobs is the number of workers calculating the metrics, GOMAXPROCS if < 1
+	Jobs int
+}
+
+func (opts ScoreOptions) scoring() *config.Scoring {
+	if opts.Scoring == nil {
+		return config.DefaultScoring()
+	}
+	return opts.Scoring
+}
+
+func (opts ScoreOptions) sinks() sinks.Catalog {
+	if opts.Sinks == nil {
+		return sinks.Default
+	}
+	return opts.Sinks
+}
+
 // CalcScore calculates the scores for a list of candidates
 // All metrics are getting normalized based against the min/max values
 // in the list
 func (candidates Candidates) CalcScore() {
-	slog.Info("calculating score for candidates")
-	maxCC := 0
-	maxLines := 0
-	// find max values for normalization
+	candidates.CalcScoreWithOptions(ScoreOptions{})
+}
+
+// CalcScoreWithOptions calculates the metrics and scores for a list of candidates
+func (candidates Candidates) CalcScoreWithOptions(opts ScoreOptions) {
+	candidates.CalculateMetrics(opts)
+	candidates.CalculateCustomMetrics()
+	candidates.Score(opts)
+}
+
+// CalculateMetrics calculates the metrics of all candidates, metrics depending on the
+// whole list (eg. the call graph) can be added before calculating the score
+func (candidates Candidates) CalculateMetrics(opts ScoreOptions) {
+	calc := NewCalculator(opts)
+
+	// the tree-sitter nodes of a file are not safe for concurrent use, so all candidates of a file
+	// are handled by the same worker
+	files := candidates.byFile()
+	helper.Parallel(len(files), opts.Jobs, func() func(i int) {
+		return func(i int) {
+			calc.Calculate(files[i])
+		}
+	})
+}
+
+// Calculator calculates the metrics file by file, eg. while other files are still parsed. It is safe for
+// concurrent use as long as the candidates of a file are passed together.
+type Calculator struct {
+	opts  ScoreOptions
+	tests map[string][]*testcase.Test
+}
+
+// NewCalculator creates a calculator for the given options, all tests have to be known already
+func NewCalculator(opts ScoreOptions) *Calculator {
+	// index the tests by the called functions
+	tests := map[string][]*testcase.Test{}
+	for _, t := range opts.Tests {
+		for _, call := range t.Calls {
+			tests[call] = append(tests[call], t)
+		}
+	}
+	return &Calculator{opts: opts, tests: tests}
+}
+
+// Calculate calculates the metrics of the candidates of a single file, metrics depending on the whole
+// list (eg. the call graph) are not included
+func (calc *Calculator) Calculate(candidates Candidates) {
+	for _, c := range candidates {
+		c.calculateMetrics(calc.opts, calc.tests[c.Function.Name])
+	}
+}
+
+// calculates all metrics of a single candidate
+func (c *Candidate) calculateMetrics(opts ScoreOptions, tests []*testcase.Test) {
+	c.CalculateMetrics()
+	c.CalculateSinks(opts.sinks())
+	c.CalculateDataFlow(opts.sinks())
+	c.LinkTests(tests)
+
+	if opts.Churn != nil {
+		if err := c.CalculateChurn(opts.Churn); err != nil {
+			slog.Debug("unable to calculate churn", "func", c.Function.Name, "error", err)
+		}
+	}
+
+	if opts.Coverage != nil {
+		if err := c.CalculateCoverage(opts.Coverage); err != nil {
+			slog.Debug("unable to calculate coverage", "func", c.Function.Name, "error", err)
+		}
+	}
+}
+
+// groups the candidates by their file, keeping the order of the first occurrence
+func (candidates Candidates) byFile() []Candidates {
+	files := []Candidates{}
+	index := map[string]int{}
 	for _, c := range candidates {
-		c.CalculateMetrics()
+		i, ok := index[c.Path]
+		if !ok {
+			i = len(files)
+			index[c.Path] = i
+			files = append(files, Candidates{})
+		}
+		files[i] = append(files[i], c)
+	}
+	return files
+}
+
+// CalculateCustomMetrics calculates the registered metrics (see Register) of all candidates, it has to be called
+// after all built-in metrics are calculated
+func (candidates Candidates) CalculateCustomMetrics() {
+	for _, c := range candidates {
+		c.CalculateCustomMetrics()
+	}
+}
+
+// Score calculates the scores based on the already calculated metrics
+// All metrics are getting normalized based against the min/max values
+// in the list
+func (candidates Candidates) Score(opts ScoreOptions) {
+	slog.Info("calculating score for candidates")
+
+	scoring := opts.scoring()
+	names := config.MetricNames()
+	values := make([]map[string]float64, len(candidates))
+	for i, c := range candidates {
+		values[i] = c.scoreValues(opts)
+	}
+
+	// normalize every metric over all candidates, boolean metrics are already in the range 0-1 and
+	// the coverage is weighted by the normalized complexity
+	booleans := append(slices.Clone(booleanMetrics), customBooleanMetrics()...)
+	norm := make([]map[string]float64, len(candidates))
+	for i := range candidates {
+		norm[i] = map[string]float64{}
+	}
+	// the raw values normalized to 1, eg. the max value or the threshold of the fixed normalization
+	maxValues := map[string]float64{}
+	// the normalized complexity weighting the coverage
+	complexity := make([]float64, len(candidates))
+	for _, name := range names {
+		column := make([]float64, len(candidates))
+		for i := range candidates {
+			column[i] = values[i][name]
+		}
+		normalized := column
+		if !slices.Contains(booleans, name) && name != "cov" {
+			normalized = normalize.Normalize(scoring.Normalization, column, scoring.Threshold(name))
+			maxValues[name] = normalize.Reference(scoring.Normalization, column, scoring.Threshold(name))
+		} else if len(column) > 0 {
+			maxValues[name] = max(slices.Max(column), 0)
+		}
+		for i := range candidates {
+			norm[i][name] = normalized[i]
+		}
+		if name == "cc" || name == "cog" {
+			// has to be in the range 0-1, the zscore is not
+			bounded := normalized
+			if scoring.Normalization == normalize.ZScore {
+				bounded = normalize.Normalize(normalize.MinMax, column, 0)
+			}
+			for i, val := range bounded {
+				complexity[i] = max(complexity[i], min(val, 1))
+			}
+		}
+	}
+
+	maxValues["cov"] = 1
 
-		if c.Metrics.LinesOfCode > maxLines {
-			maxLines = c.Metrics.LinesOfCode
+	for i, c := range candidates {
+		// the uncovered part of the function weighted by its complexity
+		norm[i]["cov"] = values[i]["cov"] * complexity[i]
+
+		if scoring.Score != nil {
+			c.Score, c.Contributions = evalScore(scoring.Score, names, values[i], maxValues, norm[i])
+			continue
 		}
 
-		if c.Metrics.CyclomaticComplexity > maxCC {
-			maxCC = c.Metrics.CyclomaticComplexity
+		// applying different weights for the single metrics, summing up in a fixed order,
+		// so equal candidates get exactly the same score
+		weights := scoring.Weights(c.Language)
+		c.Score = 0
+		c.Contributions = []*Contribution{}
+		for _, name := range names {
+			contribution := &Contribution{
+				Metric:       name,
+				Value:        values[i][name],
+				Max:          maxValues[name],
+				Normalized:   norm[i][name],
+				Weight:       weights[name],
+				Contribution: norm[i][name] * weights[name],
+			}
+			c.Score += contribution.Contribution
+			c.Contributions = append(c.Contributions, contribution)
 		}
 	}
+}
 
-	// weights for the different metrics
-	w := map[string]float64{
-		"cc":   4,
-		"loc":  1,
-		"name": 5,
-		"prim": 0, // not weighting it by now, as this is more of a filter
+// metrics with the values 0 or 1, they are not normalized
+var booleanMetrics = []string{"name", "prim", "raw", "err", "ret", "throws", "unit", "fuzz"}
+
+// returns the raw values of the metrics used for the score, the sinks are weighted and the coverage is only
+// counted if a coverage report is used
+func (c *Candidate) scoreValues(opts ScoreOptions) map[string]float64 {
+	values := c.metricValues()
+	values["sink"] = opts.sinks().Weighted(c.Metrics.Sinks)
+	values["cov"] = 0
+	if opts.Coverage != nil {
+		values["cov"] = 1 - (c.Metrics.LineCoverage+c.Metrics.BranchCoverage)/2
 	}
+	return values
+}
 
+// returns the raw values of the metrics by the names used for the score, independent of the score options:
+// sink is the number of sink calls and cov the uncovered part (0 without coverage)
+func (c *Candidate) metricValues() map[string]float64 {
 	normBool := func(val bool) float64 {
 		if val {
 			return 1
 		}
 		return 0
 	}
+	m := c.Metrics
+	if m == nil {
+		m = &metrics.Metrics{}
+	}
+	uncovered := 0.0
+	if m.HasCoverage {
+		uncovered = 1 - (m.LineCoverage+m.BranchCoverage)/2
+	}
 
-	for _, c := range candidates {
-		normCC := float64(c.Metrics.CyclomaticComplexity) / float64(maxCC)
-		normLines := float64(c.Metrics.LinesOfCode) / float64(maxLines)
-		normName := normBool(c.Metrics.FuzzFriendlyName)
-		normPrim := normBool(c.Metrics.PrimitiveParametersOnly)
-
-		// applying different weights for the single metrics
-		c.Score =
-			(normCC * w["cc"]) +
-				(normLines * w["loc"]) +
-				(normName * w["name"]) +
-				(normPrim * w["prim"])
+	values := map[string]float64{
+		"cc":      float64(m.CyclomaticComplexity),
+		"cog":     float64(m.CognitiveComplexity),
+		"loc":     float64(m.LogicalLinesOfCode),
+		"name":    normBool(m.FuzzFriendlyName),
+		"prim":    normBool(m.PrimitiveParametersOnly),
+		"raw":     normBool(m.RawInputParameters > 0),
+		"err":     normBool(m.ReturnsError),
+		"params":  float64(m.ParameterCount),
+		"ret":     normBool(m.ReturnsValue),
+		"throws":  normBool(len(c.Function.Throws) > 0),
+		"churn":   float64(m.ChurnCommits),
+		"cov":     uncovered,
+		"unit":    normBool(m.HasUnitTest),
+		"fuzz":    normBool(m.HasFuzzTest),
+		"fanin":   float64(m.FanIn),
+		"entry":   float64(m.EntryPoints),
+		"sink":    float64(m.SinkCalls),
+		"pbranch": float64(m.ParamBranches),
+		"tsink":   float64(m.TaintedSinks),
+	}
+	for _, custom := range Registered() {
+		values[custom.Name()] = m.Custom[custom.Name()]
+	}
+	return values
+}
+
+// evaluates a score expression, the contribution of a metric is the difference to the score
+// without the metric (set to 0)
+func evalScore(score *expr.Expression, names []string, values, maxValues, norm map[string]float64) (float64, []*Contribution) {
+	total, err := score.Eval(norm)
+	if err != nil {
+		slog.Warn("unable to evaluate score", "score", score, "error", err)
+		return 0, nil
+	}
+
+	contributions := []*Contribution{}
+	for _, name := range names {
+		contribution := &Contribution{
+			Metric:     name,
+			Value:      values[name],
+			Max:        maxValues[name],
+			Normalized: norm[name],
+		}
+		if slices.Contains(score.Variables(), name) {
+			without := maps.Clone(norm)
+			without[name] = 0
+			if val, err := score.Eval(without); err == nil {
+				contribution.Contribution = total - val
+			}
+		}
+		contributions = append(contributions, contribution)
 	}
+	return total, contributions
 }
 
 func (candidates Candidates) Filter(filter func(*Candidate) bool) Candidates {
@@ -1,11 +1,19 @@
 package candidate_test
 
 import (
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/jochil/gcs/pkg/candidate"
+	"github.com/jochil/gcs/pkg/config"
+	"github.com/jochil/gcs/pkg/coverage"
+	"github.com/jochil/gcs/pkg/metrics"
+	"github.com/jochil/gcs/pkg/normalize"
+	"github.com/jochil/gcs/pkg/parser"
 	"github.com/jochil/gcs/pkg/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFilter(t *testing.T) {
@@ -30,3 +38,214 @@ func TestFilter(t *testing.T) {
 	assert.Len(t, filtered, 1)
 	assert.Equal(t, "C", filtered[0].Function.Name)
 }
+
+func TestParseFilter(t *testing.T) {
+	c := &candidate.Candidate{
+		Function: &candidate.Function{Name: "parse", Visibility: types.VisibilityPrivate},
+		Class:    &candidate.Class{Name: "Parser"},
+		Language: types.Java,
+		// not scored, the metrics are read from the raw values
+		Metrics: &metrics.Metrics{CyclomaticComplexity: 7, NPathComplexity: 12, Loops: 2, UnreachableBlocks: 1},
+	}
+
+	e, err := candidate.ParseFilter(`language == "Java" && class == "Parser" && visibility == "private" && metrics.cc >= 5 && !has_fuzz_test`)
+	require.NoError(t, err)
+	ok, err := e.Match(c.FilterVariables())
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	e, err = candidate.ParseFilter(`metrics.npath > 10 && metrics.loops == 2 && metrics.unreachable_blocks > 0 && metrics.churn == 0`)
+	require.NoError(t, err)
+	ok, err = e.Match(c.FilterVariables())
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = candidate.ParseFilter("metrics.unknown > 1")
+	require.Error(t, err)
+	_, err = candidate.ParseFilter(`metrics.cc == "high"`)
+	require.Error(t, err)
+	_, err = candidate.ParseFilter(`false && name > 5`)
+	require.Error(t, err)
+}
+
+func TestScore_Contributions(t *testing.T) {
+	candidates := candidate.Candidates{}
+	for _, path := range []string{"../cfg/testdata/cyclo/golang/a.go", "../cfg/testdata/cyclo/golang/c.go", "../cfg/testdata/cyclo/golang/h.go"} {
+		nc, err := parser.ParseFile(path)
+		require.NoError(t, err)
+		candidates = append(candidates, nc...)
+	}
+	candidates.CalcScore()
+
+	for _, c := range candidates {
+		require.Len(t, c.Contributions, len(config.MetricNames()))
+		sum := 0.0
+		for _, contribution := range c.Contributions {
+			assert.InDelta(t, contribution.Normalized*contribution.Weight, contribution.Contribution, 0.0001)
+			if contribution.Max > 0 {
+				assert.InDelta(t, contribution.Value/contribution.Max, contribution.Normalized, 0.0001, contribution.Metric)
+			}
+			sum += contribution.Contribution
+		}
+		assert.InDelta(t, c.Score, sum, 0.0001)
+	}
+
+	// the most complex function gets the full share of all metrics, sorted by their weight
+	c := candidates[1]
+	contributions := c.SortedContributions()
+	require.Len(t, contributions, 4)
+	for i, metric := range []string{"pbranch", "cog", "cc", "loc"} {
+		assert.Equal(t, metric, contributions[i].Metric)
+		assert.Equal(t, 1.0, contributions[i].Normalized)
+	}
+
+	out := &strings.Builder{}
+	require.NoError(t, c.WriteContributions(out))
+	assert.Contains(t, out.String(), "Contribution")
+	assert.Contains(t, out.String(), "cog")
+	assert.NotContains(t, out.String(), "churn")
+}
+
+func TestScore_ExpressionContributions(t *testing.T) {
+	cfg := &config.Config{Profiles: map[string]*config.Profile{"test": {Score: "2*cc + loc * cc"}}}
+	scoring, err := cfg.Scoring("test")
+	require.NoError(t, err)
+
+	candidates, err := parser.ParseFile("../cfg/testdata/cyclo/golang/c.go")
+
+	require.NoError(t, err)
+	candidates.CalcScoreWithOptions(candidate.ScoreOptions{Scoring: scoring})
+	require.Len(t, candidates, 1)
+	c := candidates[0]
+	assert.Equal(t, 3.0, c.Score)
+
+	for _, contribution := range c.Contributions {
+		switch contribution.Metric {
+		case "cc":
+			assert.Equal(t, 3.0, contribution.Contribution)
+		case "loc":
+			assert.Equal(t, 1.0, contribution.Contribution)
+		default:
+			assert.Zero(t, contribution.Contribution)
+		}
+		assert.Zero(t, contribution.Weight)
+	}
+}
+
+func TestScore_ReturnShape(t *testing.T) {
+	cfg := &config.Config{Profiles: map[string]*config.Profile{"test": {Weights: config.Weights{"ret": 1, "throws": 10}}}}
+	scoring, err := cfg.Scoring("test")
+	require.NoError(t, err)
+
+	candidates := candidate.Candidates{}
+	for _, path := range []string{"../parser/testdata/java/parameter.java", "../cfg/testdata/cyclo/golang/b.go"} {
+		nc, err := parser.ParseFile(path)
+		require.NoError(t, err)
+		candidates = append(candidates, nc...)
+	}
+	candidates.CalcScoreWithOptions(candidate.ScoreOptions{Scoring: scoring})
+	require.Len(t, candidates, 4)
+
+	e, err := candidate.ParseFilter("throws && returns_error && !returns_value")
+	require.NoError(t, err)
+
+	expected := map[string]map[string]float64{
+		"Spread":  {"ret": 0, "throws": 0},
+		"Generic": {"ret": 0, "throws": 0},
+		"Throws":  {"ret": 0, "throws": 10},
+		"CycloB":  {"ret": 1, "throws": 0},
+	}
+	for _, c := range candidates {
+		ok, err := e.Match(c.FilterVariables())
+		require.NoError(t, err)
+		assert.Equal(t, c.Function.Name == "Throws", ok, "wrong filter result for %s", c.Function.Name)
+
+		found := 0
+		for _, contribution := range c.Contributions {
+			if want, ok := expected[c.Function.Name][contribution.Metric]; ok {
+				assert.Equal(t, want, contribution.Contribution, "wrong %s for %s", contribution.Metric, c.Function.Name)
+				found++
+			}
+		}
+		assert.Equal(t, 2, found, "missing contributions for %s", c.Function.Name)
+	}
+}
+
+func TestScore_Normalization(t *testing.T) {
+	parse := func(paths ...string) candidate.Candidates {
+		candidates := candidate.Candidates{}
+		for _, path := range paths {
+			nc, err := parser.ParseFile(path)
+			require.NoError(t, err)
+			candidates = append(candidates, nc...)
+		}
+		return candidates
+	}
+	paths := []string{"../cfg/testdata/cyclo/golang/a.go", "../cfg/testdata/cyclo/golang/c.go", "../cfg/testdata/cyclo/golang/h.go"}
+
+	for _, strategy := range normalize.Strategies {
+		t.Run(string(strategy), func(t *testing.T) {
+			scoring := config.DefaultScoring()
+			require.NoError(t, scoring.SetNormalization(string(strategy)))
+			opts := candidate.ScoreOptions{Scoring: scoring}
+
+			all := parse(paths...)
+			all.CalcScoreWithOptions(opts)
+			subset := parse(paths[0])
+			subset.CalcScoreWithOptions(opts)
+
+			for _, c := range append(all, subset...) {
+				assert.False(t, math.IsNaN(c.Score), "score of %s is NaN", c.Function.Name)
+			}
+			// the fixed thresholds are independent of the other candidates
+			switch strategy {
+			case normalize.Fixed:
+				assert.Equal(t, all[0].Score, subset[0].Score)
+			case normalize.Max:
+				assert.NotEqual(t, all[0].Score, subset[0].Score)
+			}
+		})
+	}
+}
+
+func TestScore_ZScore(t *testing.T) {
+	candidates := candidate.Candidates{}
+	for _, path := range []string{"../cfg/testdata/cyclo/golang/a.go", "../cfg/testdata/cyclo/golang/c.go", "../cfg/testdata/cyclo/golang/h.go"} {
+		nc, err := parser.ParseFile(path)
+		require.NoError(t, err)
+		candidates = append(candidates, nc...)
+	}
+	scoring := config.DefaultScoring()
+	require.NoError(t, scoring.SetNormalization("zscore"))
+	// no file is covered by an empty profile
+	candidates.CalcScoreWithOptions(candidate.ScoreOptions{Scoring: scoring, Coverage: coverage.NewProfile()})
+
+	cc := []float64{}
+	for _, c := range candidates {
+		cc = append(cc, float64(c.Metrics.CyclomaticComplexity))
+	}
+	for _, c := range candidates {
+		for _, contribution := range c.Contributions {
+			switch contribution.Metric {
+			case "cov":
+				// weighted by the complexity in the range 0-1, even if the zscore of the complexity is negative
+				assert.GreaterOrEqual(t, contribution.Normalized, 0.0, c.Function.Name)
+				assert.LessOrEqual(t, contribution.Normalized, 1.0, c.Function.Name)
+			case "cc":
+				assert.Equal(t, normalize.Reference(normalize.ZScore, cc, 0), contribution.Max)
+			}
+		}
+	}
+}
+
+func TestScore_Empty(t *testing.T) {
+	// candidates without body have no lines of code and no complexity
+	candidates := candidate.Candidates{
+		{Function: &candidate.Function{Name: "A"}},
+		{Function: &candidate.Function{Name: "B"}},
+	}
+	candidates.CalcScore()
+	for _, c := range candidates {
+		assert.False(t, math.IsNaN(c.Score))
+	}
+}
@@ -0,0 +1,55 @@
+package candidate
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+)
+
+// Contribution is the share of a single metric in the score
+type Contribution struct {
+	Metric string `json:"metric"`
+	// Value is the raw value of the metric
+	Value float64 `json:"value"`
+	// Max is the raw value normalized to 1 by the normalization strategy, eg. the max value of all candidates, the
+	// mean plus one standard deviation for zscore or the threshold for fixed
+	Max        float64 `json:"max"`
+	Normalized float64 `json:"normalized"`
+	// Weight is not set if the score is calculated by an expression
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+// SortedContributions returns the contributions sorted by their absolute value, metrics
+// without contribution are skipped
+func (c *Candidate) SortedContributions() []*Contribution {
+	contributions := []*Contribution{}
+	for _, contribution := range c.Contributions {
+		if contribution.Contribution != 0 {
+			contributions = append(contributions, contribution)
+		}
+	}
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return math.Abs(contributions[i].Contribution) > math.Abs(contributions[j].Contribution)
+	})
+	return contributions
+}
+
+// WriteContributions writes the contributions as table
+func (c *Candidate) WriteContributions(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Metric\tValue\tMax\tNormalized\tWeight\tContribution")
+	for _, contribution := range c.SortedContributions() {
+		fmt.Fprintf(tw, "%s\t%.2f\t%.2f\t%.2f\t%.2f\t%+.2f\n",
+			contribution.Metric,
+			contribution.Value,
+			contribution.Max,
+			contribution.Normalized,
+			contribution.Weight,
+			contribution.Contribution,
+		)
+	}
+	return tw.Flush()
+}
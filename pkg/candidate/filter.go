@@ -0,0 +1,122 @@
+package candidate
+
+import (
+	"strings"
+
+	"github.com/jochil/gcs/pkg/config"
+	"github.com/jochil/gcs/pkg/expr"
+	"github.com/jochil/gcs/pkg/metrics"
+)
+
+// fields of a candidate usable in filter expressions with their types, the metrics are added as
+// "metrics.<name>" (see filterMetrics)
+var filterFields = []struct {
+	name string
+	kind expr.Type
+}{
+	{"name", expr.String}, {"class", expr.String}, {"package", expr.String}, {"path", expr.String},
+	{"language", expr.String}, {"visibility", expr.String}, {"static", expr.Number}, {"score", expr.Number},
+	{"returns_error", expr.Number}, {"returns_value", expr.Number}, {"throws", expr.Number},
+	{"has_unit_test", expr.Number}, {"has_fuzz_test", expr.Number}, {"has_coverage", expr.Number},
+	{"reachable", expr.Number},
+}
+
+// raw metric only usable in filter expressions, in addition to the metrics named like in the score
+type filterMetric struct {
+	name  string
+	value func(m *metrics.Metrics) float64
+}
+
+var filterMetrics = []filterMetric{
+	{"lines_of_code", func(m *metrics.Metrics) float64 { return float64(m.LinesOfCode) }},
+	{"comment_lines", func(m *metrics.Metrics) float64 { return float64(m.CommentLines) }},
+	{"npath", func(m *metrics.Metrics) float64 { return float64(m.NPathComplexity) }},
+	{"halstead_volume", func(m *metrics.Metrics) float64 { return m.HalsteadVolume }},
+	{"maintainability", func(m *metrics.Metrics) float64 { return m.MaintainabilityIndex }},
+	{"loops", func(m *metrics.Metrics) float64 { return float64(m.Loops) }},
+	{"max_loop_depth", func(m *metrics.Metrics) float64 { return float64(m.MaxLoopDepth) }},
+	{"unreachable_blocks", func(m *metrics.Metrics) float64 { return float64(m.UnreachableBlocks) }},
+	{"churn_lines", func(m *metrics.Metrics) float64 { return float64(m.ChurnLinesChanged) }},
+	{"churn_authors", func(m *metrics.Metrics) float64 { return float64(m.ChurnAuthors) }},
+	{"line_coverage", func(m *metrics.Metrics) float64 { return m.LineCoverage }},
+	{"branch_coverage", func(m *metrics.Metrics) float64 { return m.BranchCoverage }},
+	{"fan_out", func(m *metrics.Metrics) float64 { return float64(m.FanOut) }},
+	{"tainted_loops", func(m *metrics.Metrics) float64 { return float64(m.TaintedLoops) }},
+	{"tainted_indexes", func(m *metrics.Metrics) float64 { return float64(m.TaintedIndexes) }},
+}
+
+// returns the names of all metrics usable in filter expressions
+func filterMetricNames() []string {
+	names := config.MetricNames()
+	for _, metric := range filterMetrics {
+		names = append(names, metric.name)
+	}
+	return names
+}
+
+// FilterVariableNames returns the names of all variables usable in filter expressions
+func FilterVariableNames() []string {
+	names := []string{}
+	for _, field := range filterFields {
+		names = append(names, field.name)
+	}
+	for _, name := range filterMetricNames() {
+		names = append(names, "metrics."+name)
+	}
+	return names
+}
+
+// FilterTypes returns the types of all variables usable in filter expressions
+func FilterTypes() expr.Types {
+	types := expr.Types{}
+	for _, field := range filterFields {
+		types[field.name] = field.kind
+	}
+	for _, name := range filterMetricNames() {
+		types["metrics."+name] = expr.Number
+	}
+	return types
+}
+
+// ParseFilter parses a filter expression (eg. `language == "Go" && metrics.cc >= 5`) and checks the
+// used variables and types
+func ParseFilter(source string) (*expr.Expression, error) {
+	return expr.ParseFilter(source, FilterTypes())
+}
+
+// FilterVariables returns the values of the candidate used in filter expressions, the metrics are the raw
+// values calculated for the candidate, independent of the score
+func (c *Candidate) FilterVariables() expr.Variables {
+	variables := expr.Variables{
+		"name":       c.Function.Name,
+		"class":      "",
+		"package":    c.Package,
+		"path":       c.Path,
+		"language":   c.Language.String(),
+		"visibility": strings.ToLower(c.Function.Visibility),
+		"static":     c.Function.Static,
+		"score":      c.Score,
+	}
+	if c.Class != nil {
+		variables["class"] = c.Class.Name
+	}
+	m := c.Metrics
+	if m == nil {
+		m = &metrics.Metrics{}
+	}
+	variables["returns_error"] = m.ReturnsError
+	variables["returns_value"] = m.ReturnsValue
+	variables["throws"] = len(c.Function.Throws) > 0
+	variables["has_unit_test"] = m.HasUnitTest
+	variables["has_fuzz_test"] = m.HasFuzzTest
+	variables["has_coverage"] = m.HasCoverage
+	variables["reachable"] = m.Reachable
+
+	for name, value := range c.metricValues() {
+		variables["metrics."+name] = value
+	}
+	for _, metric := range filterMetrics {
+		variables["metrics."+metric.name] = metric.value(m)
+	}
+	return variables
+}
@@ -0,0 +1,145 @@
+package candidate
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/jochil/gcs/pkg/config"
+	"github.com/jochil/gcs/pkg/metrics"
+	"github.com/jochil/gcs/pkg/types"
+)
+
+// ValueType is the type of the value of a metric
+type ValueType int
+
+const (
+	// Number values are normalized like the built-in numeric metrics (eg. lines of code)
+	Number ValueType = iota
+	// Bool values are 0 or 1 and are not normalized
+	Bool
+)
+
+// Metric is a custom metric calculated for every candidate, the result is stored in metrics.Metrics.Custom
+// and can be used in weights, score expressions and filters by its name
+type Metric interface {
+	// Name has to be a valid identifier (eg. "todo_comments") not used by a built-in metric
+	Name() string
+	// Languages are the supported languages, all languages are supported if empty
+	Languages() []types.Language
+	Type() ValueType
+	// Threshold is the value normalized to 1 by the fixed normalization (only used by Number metrics), without a
+	// threshold (0) the config has to set one if the metric is weighted
+	Threshold() float64
+	// Calculate is called after the built-in metrics (including the call graph) are calculated, so it can use
+	// the AST, the CFG and all other metrics of the candidate. Streamed candidates have no AST anymore.
+	Calculate(c *Candidate) (float64, error)
+}
+
+type metricFunc struct {
+	name      string
+	valueType ValueType
+	threshold float64
+	languages []types.Language
+	calculate func(c *Candidate) (float64, error)
+}
+
+func (m *metricFunc) Name() string                            { return m.name }
+func (m *metricFunc) Languages() []types.Language             { return m.languages }
+func (m *metricFunc) Type() ValueType                         { return m.valueType }
+func (m *metricFunc) Threshold() float64                      { return m.threshold }
+func (m *metricFunc) Calculate(c *Candidate) (float64, error) { return m.calculate(c) }
+
+// NewMetric creates a metric from a function, the threshold is used by the fixed normalization
+func NewMetric(name string, valueType ValueType, threshold float64, languages []types.Language, calculate func(c *Candidate) (float64, error)) Metric {
+	return &metricFunc{name: name, valueType: valueType, threshold: threshold, languages: languages, calculate: calculate}
+}
+
+var (
+	registry   = map[string]Metric{}
+	registryMu sync.RWMutex
+	metricName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Register adds a custom metric
+func Register(m Metric) error {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	name := m.Name()
+	if !metricName.MatchString(name) {
+		return fmt.Errorf("invalid metric name %q", name)
+	}
+	if slices.ContainsFunc(filterMetrics, func(f filterMetric) bool { return f.name == name }) {
+		return fmt.Errorf("metric %s already exists", name)
+	}
+	threshold := m.Threshold()
+	if m.Type() == Bool {
+		// not normalized, 1 is the max value
+		threshold = 1
+	}
+	if err := config.AddMetric(name, threshold); err != nil {
+		return err
+	}
+	registry[name] = m
+	return nil
+}
+
+// Unregister removes a custom metric
+func Unregister(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[name]; ok {
+		delete(registry, name)
+		config.RemoveMetric(name)
+	}
+}
+
+// Registered returns all custom metrics sorted by their name
+func Registered() []Metric {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	registered := []Metric{}
+	for _, m := range registry {
+		registered = append(registered, m)
+	}
+	sort.Slice(registered, func(i, j int) bool {
+		return registered[i].Name() < registered[j].Name()
+	})
+	return registered
+}
+
+// CalculateCustomMetrics calculates all registered metrics supporting the language of the candidate
+func (c *Candidate) CalculateCustomMetrics() {
+	if c.Metrics == nil {
+		c.Metrics = &metrics.Metrics{}
+	}
+	c.Metrics.Custom = map[string]float64{}
+	for _, m := range Registered() {
+		if len(m.Languages()) > 0 && !slices.Contains(m.Languages(), c.Language) {
+			continue
+		}
+		val, err := m.Calculate(c)
+		if err != nil {
+			slog.Debug("unable to calculate metric", "metric", m.Name(), "func", c.Function.Name, "error", err)
+			continue
+		}
+		c.Metrics.Custom[m.Name()] = val
+	}
+}
+
+// returns the names of the custom metrics with boolean values
+func customBooleanMetrics() []string {
+	names := []string{}
+	for _, m := range Registered() {
+		if m.Type() == Bool {
+			names = append(names, m.Name())
+		}
+	}
+	return names
+}
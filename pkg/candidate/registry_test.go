@@ -0,0 +1,166 @@
+package candidate_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jochil/gcs/pkg/candidate"
+	"github.com/jochil/gcs/pkg/config"
+	"github.com/jochil/gcs/pkg/parser"
+	"github.com/jochil/gcs/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func register(t *testing.T, m candidate.Metric) {
+	t.Helper()
+	require.NoError(t, candidate.Register(m))
+	t.Cleanup(func() {
+		candidate.Unregister(m.Name())
+	})
+}
+
+func TestRegister(t *testing.T) {
+	register(t, candidate.NewMetric("todo", candidate.Number, 5, []types.Language{types.Go}, func(c *candidate.Candidate) (float64, error) {
+		return float64(strings.Count(c.Code, "TODO")), nil
+	}))
+	register(t, candidate.NewMetric("private", candidate.Bool, 0, nil, func(c *candidate.Candidate) (float64, error) {
+		if c.Function.Visibility == types.VisibilityPrivate {
+			return 1, nil
+		}
+		return 0, nil
+	}))
+	register(t, candidate.NewMetric("broken", candidate.Number, 0, nil, func(c *candidate.Candidate) (float64, error) {
+		return 0, errors.New("broken")
+	}))
+	register(t, candidate.NewMetric("java", candidate.Number, 0, []types.Language{types.Java}, func(c *candidate.Candidate) (float64, error) {
+		return 1, nil
+	}))
+
+	require.Error(t, candidate.Register(candidate.NewMetric("todo", candidate.Number, 0, nil, nil)), "duplicate metric")
+	require.Error(t, candidate.Register(candidate.NewMetric("cc", candidate.Number, 0, nil, nil)), "built-in metric")
+	require.Error(t, candidate.Register(candidate.NewMetric("npath", candidate.Number, 0, nil, nil)), "filter metric")
+	require.Error(t, candidate.Register(candidate.NewMetric("todo-count", candidate.Number, 0, nil, nil)), "invalid name")
+	require.Len(t, candidate.Registered(), 4)
+	assert.Equal(t, "broken", candidate.Registered()[0].Name())
+
+	// the custom metrics can be used by weights
+	cfg := &config.Config{Weights: config.Weights{"todo": 10, "private": 1}}
+	scoring, err := cfg.Scoring("")
+	require.NoError(t, err)
+
+	candidates, err := parser.ParseFile("testdata/todo.go")
+
+	require.NoError(t, err)
+	candidates.CalcScoreWithOptions(candidate.ScoreOptions{Scoring: scoring})
+	require.Len(t, candidates, 2)
+
+	a, b := candidates[0], candidates[1]
+	assert.Equal(t, map[string]float64{"todo": 2, "private": 0}, a.Metrics.Custom)
+	assert.Equal(t, map[string]float64{"todo": 0, "private": 1}, b.Metrics.Custom)
+
+	for _, contribution := range a.Contributions {
+		if contribution.Metric == "todo" {
+			assert.Equal(t, 10.0, contribution.Contribution)
+		}
+	}
+	for _, contribution := range b.Contributions {
+		if contribution.Metric == "private" {
+			assert.Equal(t, 1.0, contribution.Contribution)
+		}
+	}
+
+	// and by filters, also without a score
+	e, err := candidate.ParseFilter("metrics.todo == 2 && metrics.private == 0")
+	require.NoError(t, err)
+	unscored := *a
+	unscored.Contributions = nil
+	ok, err := e.Match(unscored.FilterVariables())
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	out, err := json.Marshal(a.Metrics)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `"Custom":{"private":0,"todo":2}`)
+}
+
+func TestRegister_Fixed(t *testing.T) {
+	register(t, candidate.NewMetric("todo", candidate.Number, 5, nil, func(c *candidate.Candidate) (float64, error) {
+		return float64(strings.Count(c.Code, "TODO")), nil
+	}))
+	register(t, candidate.NewMetric("lines", candidate.Number, 0, nil, func(c *candidate.Candidate) (float64, error) {
+		return float64(strings.Count(c.Code, "\n")), nil
+	}))
+	register(t, candidate.NewMetric("private", candidate.Bool, 0, nil, func(c *candidate.Candidate) (float64, error) {
+		return 1, nil
+	}))
+
+	cfg := &config.Config{Normalization: "fixed", Weights: config.Weights{"todo": 10, "private": 1}}
+	scoring, err := cfg.Scoring("")
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, scoring.Threshold("todo"))
+
+	candidates, err := parser.ParseFile("testdata/todo.go")
+	require.NoError(t, err)
+	candidates.CalcScoreWithOptions(candidate.ScoreOptions{Scoring: scoring})
+	for _, contribution := range candidates[0].Contributions {
+		switch contribution.Metric {
+		case "todo":
+			assert.Equal(t, 0.4, contribution.Normalized)
+			assert.Equal(t, 4.0, contribution.Contribution)
+		case "private":
+			assert.Equal(t, 1.0, contribution.Contribution)
+		}
+	}
+
+	// weighted metrics without a threshold can't be normalized
+	cfg.Weights["lines"] = 1
+	_, err = cfg.Scoring("")
+	require.ErrorContains(t, err, "lines has no threshold")
+
+	cfg.Thresholds = config.Weights{"lines": 20}
+	scoring, err = cfg.Scoring("")
+	require.NoError(t, err)
+	assert.Equal(t, 20.0, scoring.Threshold("lines"))
+}
+
+func TestUnregister(t *testing.T) {
+	require.NoError(t, candidate.Register(candidate.NewMetric("tmp", candidate.Number, 0, nil, nil)))
+	assert.Contains(t, config.MetricNames(), "tmp")
+
+	candidate.Unregister("tmp")
+	assert.NotContains(t, config.MetricNames(), "tmp")
+	assert.Empty(t, candidate.Registered())
+
+	// built-in metrics can't be removed
+	candidate.Unregister("cc")
+	assert.Contains(t, config.MetricNames(), "cc")
+}
+
+func TestRegister_Concurrent(t *testing.T) {
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		name := fmt.Sprintf("concurrent%d", i)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				assert.NoError(t, candidate.Register(candidate.NewMetric(name, candidate.Number, 0, nil, nil)))
+				candidate.Unregister(name)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				assert.Contains(t, config.MetricNames(), "cc")
+				assert.Contains(t, candidate.FilterVariableNames(), "metrics.cc")
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Empty(t, candidate.Registered())
+}
@@ -0,0 +1,11 @@
+package todo
+
+func A(a int) int {
+	// TODO handle negative values
+	// TODO overflow
+	return a * 2
+}
+
+func b(b int) int {
+	return b
+}
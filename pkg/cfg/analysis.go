@@ -0,0 +1,249 @@
+package cfg
+
+import (
+	"slices"
+)
+
+const (
+	// StartRef is the reference of the start vertex of every control flow graph
+	StartRef = 0
+	// EndRef is the reference of the end vertex of every control flow graph
+	EndRef = 1
+)
+
+// Loop describes a natural loop inside of the control flow graph
+type Loop struct {
+	// Header is the vertex every path into the loop has to pass
+	Header int `json:"header"`
+	// Body contains all vertices of the loop (including the header), sorted by reference
+	Body []int `json:"body"`
+	// Depth is the nesting depth of the loop, starting with 1 for outermost loops
+	Depth int `json:"depth"`
+	// Parent is the innermost loop containing this loop, nil for outermost loops
+	Parent *Loop `json:"-"`
+}
+
+// Analysis holds the results of analysing a control flow graph
+type Analysis struct {
+	// Dominators maps every reachable vertex to its immediate dominator,
+	// the start vertex has no entry
+	Dominators map[int]int
+	// PostDominators maps every vertex reaching the end to its immediate post-dominator,
+	// the end vertex has no entry
+	PostDominators map[int]int
+	// Loops contains all natural loops, ordered by their header
+	Loops []*Loop
+	// Unreachable contains all vertices that can not be reached from the start vertex
+	Unreachable []int
+}
+
+// MaxLoopDepth returns the deepest nesting level of all loops, 0 if there are no loops
+func (a *Analysis) MaxLoopDepth() int {
+	depth := 0
+	for _, l := range a.Loops {
+		depth = max(depth, l.Depth)
+	}
+	return depth
+}
+
+// Dominates checks if every path from the start vertex to y passes x
+func (a *Analysis) Dominates(x, y int) bool {
+	return dominates(a.Dominators, x, y)
+}
+
+// PostDominates checks if every path from y to the end vertex passes x
+func (a *Analysis) PostDominates(x, y int) bool {
+	return dominates(a.PostDominators, x, y)
+}
+
+// Analyze calculates dominators, post-dominators, natural loops and unreachable vertices of a graph
+func Analyze(g Graph) (*Analysis, error) {
+	successors, err := g.AdjacencyMap()
+	if err != nil {
+		return nil, err
+	}
+	predecessors, err := g.PredecessorMap()
+	if err != nil {
+		return nil, err
+	}
+
+	succ := sortedRefs(successors)
+	pred := sortedRefs(predecessors)
+
+	a := &Analysis{
+		Dominators:     immediateDominators(StartRef, succ, pred),
+		PostDominators: immediateDominators(EndRef, pred, succ),
+		Unreachable:    []int{},
+	}
+
+	for ref := range successors {
+		if _, ok := a.Dominators[ref]; !ok && ref != StartRef {
+			a.Unreachable = append(a.Unreachable, ref)
+		}
+	}
+	slices.Sort(a.Unreachable)
+
+	a.Loops = naturalLoops(a.Dominators, succ, pred)
+
+	return a, nil
+}
+
+// converts an adjacency map into lists of references with a stable order
+func sortedRefs[T any](adjacencyMap map[int]map[int]T) map[int][]int {
+	refs := map[int][]int{}
+	for ref, adjacent := range adjacencyMap {
+		refs[ref] = []int{}
+		for a := range adjacent {
+			refs[ref] = append(refs[ref], a)
+		}
+		slices.Sort(refs[ref])
+	}
+	return refs
+}
+
+// checks if x dominates y by walking up the dominator tree
+func dominates(idom map[int]int, x, y int) bool {
+	for {
+		if x == y {
+			return true
+		}
+		parent, ok := idom[y]
+		if !ok {
+			return false
+		}
+		y = parent
+	}
+}
+
+// returns the vertices reachable from root in reverse postorder
+func reversePostorder(root int, succ map[int][]int) []int {
+	order := []int{}
+	visited := map[int]bool{}
+	var visit func(int)
+	visit = func(v int) {
+		visited[v] = true
+		for _, s := range succ[v] {
+			if !visited[s] {
+				visit(s)
+			}
+		}
+		order = append(order, v)
+	}
+	visit(root)
+	slices.Reverse(order)
+	return order
+}
+
+// calculates the immediate dominators using the iterative algorithm by Cooper, Harvey and Kennedy
+// (http://www.hipersoft.rice.edu/grads/publications/dom14.pdf). Post-dominators are calculated
+// by passing the end vertex with swapped successors and predecessors.
+func immediateDominators(root int, succ map[int][]int, pred map[int][]int) map[int]int {
+	order := reversePostorder(root, succ)
+	position := map[int]int{}
+	for i, v := range order {
+		position[v] = i
+	}
+
+	idom := map[int]int{root: root}
+	intersect := func(a, b int) int {
+		for a != b {
+			for position[a] > position[b] {
+				a = idom[a]
+			}
+			for position[b] > position[a] {
+				b = idom[b]
+			}
+		}
+		return a
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, v := range order[1:] {
+			newIdom := -1
+			for _, p := range pred[v] {
+				if _, ok := idom[p]; !ok {
+					continue
+				}
+				if newIdom == -1 {
+					newIdom = p
+				} else {
+					newIdom = intersect(p, newIdom)
+				}
+			}
+			if current, ok := idom[v]; !ok || current != newIdom {
+				idom[v] = newIdom
+				changed = true
+			}
+		}
+	}
+
+	delete(idom, root)
+	return idom
+}
+
+// finds all natural loops, a loop is defined by a back edge n -> h where h dominates n.
+// Back edges sharing the same header are merged into a single loop.
+func naturalLoops(idom map[int]int, succ map[int][]int, pred map[int][]int) []*Loop {
+	bodies := map[int]map[int]bool{}
+	for n, targets := range succ {
+		for _, h := range targets {
+			if _, reachable := idom[n]; !reachable && n != StartRef {
+				continue
+			}
+			if !dominates(idom, h, n) {
+				continue
+			}
+
+			body, ok := bodies[h]
+			if !ok {
+				body = map[int]bool{h: true}
+				bodies[h] = body
+			}
+
+			// collect all vertices reaching n without passing the header
+			stack := []int{n}
+			for len(stack) > 0 {
+				v := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if _, reachable := idom[v]; body[v] || !reachable {
+					continue
+				}
+				body[v] = true
+				stack = append(stack, pred[v]...)
+			}
+		}
+	}
+
+	loops := []*Loop{}
+	for h, body := range bodies {
+		l := &Loop{Header: h, Body: []int{}}
+		for v := range body {
+			l.Body = append(l.Body, v)
+		}
+		slices.Sort(l.Body)
+		loops = append(loops, l)
+	}
+	slices.SortFunc(loops, func(a, b *Loop) int {
+		return a.Header - b.Header
+	})
+
+	// the parent is the smallest other loop containing the header
+	for _, l := range loops {
+		for _, other := range loops {
+			if other == l || !slices.Contains(other.Body, l.Header) {
+				continue
+			}
+			if l.Parent == nil || len(other.Body) < len(l.Parent.Body) {
+				l.Parent = other
+			}
+		}
+	}
+	for _, l := range loops {
+		for p := l; p != nil; p = p.Parent {
+			l.Depth++
+		}
+	}
+
+	return loops
+}
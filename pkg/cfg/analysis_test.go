@@ -0,0 +1,99 @@
+package cfg_test
+
+import (
+	"testing"
+
+	"github.com/jochil/gcs/pkg/cfg"
+	"github.com/jochil/gcs/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func analyze(t *testing.T, path string) *cfg.Analysis {
+	t.Helper()
+	candidates, err := parser.ParseFile(path)
+	require.NoError(t, err)
+	candidates.CalcScore()
+	analysis, err := cfg.Analyze(candidates[0].ControlFlowGraph)
+	require.NoError(t, err)
+	return analysis
+}
+
+func TestAnalyze_Dominators(t *testing.T) {
+	analysis := analyze(t, "testdata/cyclo/golang/h.go")
+
+	assert.Equal(t, map[int]int{1: 8, 2: 0, 3: 2, 4: 3, 5: 3, 6: 5, 7: 5, 8: 4}, analysis.Dominators)
+	assert.True(t, analysis.Dominates(3, 7), "outer loop header should dominate inner body")
+	assert.False(t, analysis.Dominates(7, 3))
+	assert.True(t, analysis.PostDominates(8, 2), "return should post-dominate everything before")
+	assert.False(t, analysis.PostDominates(2, 3))
+}
+
+func TestAnalyze_PostDominators(t *testing.T) {
+	analysis := analyze(t, "testdata/cyclo/golang/b.go")
+
+	// the consequence returns (4), so only the end post-dominates the if_start (2)
+	assert.Equal(t, cfg.EndRef, analysis.PostDominators[2])
+	assert.Equal(t, cfg.EndRef, analysis.PostDominators[4])
+	// the if_end (3) is followed by the final return (5)
+	assert.Equal(t, 5, analysis.PostDominators[3])
+	assert.NotContains(t, analysis.PostDominators, cfg.EndRef)
+}
+
+func TestAnalyze_Loops(t *testing.T) {
+	tests := map[string]struct {
+		path     string
+		loops    int
+		maxDepth int
+		headers  []int
+	}{
+		"go_no_control":    {path: "testdata/cyclo/golang/a.go"},
+		"go_if_else":       {path: "testdata/cyclo/golang/c.go"},
+		"go_for":           {path: "testdata/cyclo/golang/f.go", loops: 1, maxDepth: 1, headers: []int{2}},
+		"go_nested_for":    {path: "testdata/cyclo/golang/h.go", loops: 2, maxDepth: 2, headers: []int{3, 5}},
+		"java_while":       {path: "testdata/cyclo/java/While.java", loops: 1, maxDepth: 1, headers: []int{3}},
+		"java_do":          {path: "testdata/cyclo/java/Do.java", loops: 1, maxDepth: 1, headers: []int{3}},
+		"javascript_while": {path: "testdata/cyclo/javascript/while.js", loops: 1, maxDepth: 1, headers: []int{3}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			analysis := analyze(t, tc.path)
+			assert.Len(t, analysis.Loops, tc.loops, "wrong amount of loops")
+			assert.Equal(t, tc.maxDepth, analysis.MaxLoopDepth(), "wrong nesting depth")
+			for i, h := range tc.headers {
+				assert.Equal(t, h, analysis.Loops[i].Header, "wrong loop header")
+			}
+			assert.Empty(t, analysis.Unreachable)
+		})
+	}
+}
+
+func TestAnalyze_NestedLoops(t *testing.T) {
+	analysis := analyze(t, "testdata/cyclo/golang/h.go")
+	require.Len(t, analysis.Loops, 2)
+
+	outer, inner := analysis.Loops[0], analysis.Loops[1]
+	// the for_end vertices are the exits of the loops
+	assert.Equal(t, []int{3, 5, 6, 7}, outer.Body)
+	assert.Nil(t, outer.Parent)
+	assert.Equal(t, 1, outer.Depth)
+
+	assert.Equal(t, []int{5, 7}, inner.Body)
+	assert.Equal(t, outer, inner.Parent)
+	assert.Equal(t, 2, inner.Depth)
+}
+
+func TestAnalyze_Unreachable(t *testing.T) {
+	analysis := analyze(t, "testdata/cyclo/golang/k.go")
+
+	// the statements after continue (7), break (10) and return (15)
+	assert.Equal(t, []int{7, 10, 15}, analysis.Unreachable)
+	assert.NotContains(t, analysis.Dominators, 15)
+
+	// continue and break leave the loop body early
+	require.Len(t, analysis.Loops, 1)
+	assert.Equal(t, []int{3, 5, 6, 8, 9, 11}, analysis.Loops[0].Body)
+	assert.Equal(t, 4, analysis.PostDominators[8], "break should continue after the loop")
+	assert.Equal(t, cfg.EndRef, analysis.PostDominators[14])
+}
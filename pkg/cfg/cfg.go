@@ -9,143 +9,265 @@ import (
 	sitter "github.com/smacker/go-tree-sitter"
 )
 
+// Statement describes a single statement that is part of a block
+type Statement struct {
+	Type      string `json:"type"`
+	StartByte uint32 `json:"start_byte"`
+	EndByte   uint32 `json:"end_byte"`
+	StartLine uint32 `json:"start_line"`
+	EndLine   uint32 `json:"end_line"`
+}
+
+// Block is a single vertex of the control flow graph. Straight-line code is
+// collapsed into one block of kind "block", control structures create their
+// own vertices (eg. "if_start", "for_end"), an empty branch gets a "join"
+// vertex. Lines are 1-based, the span of a vertex without any source
+// (start/end/join) is zero.
+type Block struct {
+	ID         int          `json:"id"`
+	Kind       string       `json:"kind"`
+	StartByte  uint32       `json:"start_byte"`
+	EndByte    uint32       `json:"end_byte"`
+	StartLine  uint32       `json:"start_line"`
+	EndLine    uint32       `json:"end_line"`
+	Statements []*Statement `json:"statements,omitempty"`
+	// Condition contains the source code of the condition for branching vertices (eg. "if_start")
+	Condition string `json:"condition,omitempty"`
+}
+
+// EdgeKind describes why the control flow follows an edge
+type EdgeKind string
+
+const (
+	EdgeTrue      EdgeKind = "true"
+	EdgeFalse     EdgeKind = "false"
+	EdgeCase      EdgeKind = "case"
+	EdgeDefault   EdgeKind = "default"
+	EdgeLoopBack  EdgeKind = "loop-back"
+	EdgeException EdgeKind = "exception"
+)
+
+// label used for drawing an edge, the condition is stored as separate attribute
+func (k EdgeKind) label(condition string) string {
+	switch k {
+	case EdgeCase:
+		return fmt.Sprintf("case %s", condition)
+	case EdgeException:
+		return fmt.Sprintf("catch %s", condition)
+	}
+	return string(k)
+}
+
+// flow is an open end of the control flow, the next vertex is connected to ref by an edge of the given kind
+// (empty for unconditional edges)
+type flow struct {
+	ref       int
+	kind      EdgeKind
+	condition string
+}
+
+// unreachable is the flow after a return, break or continue statement, the following statements are not
+// connected with the graph
+var unreachable = flow{ref: -1}
+
+// jumpTarget holds the targets of break and continue statements inside of a loop or switch statement
+type jumpTarget struct {
+	breakRef int
+	// -1 for switch statements
+	continueRef int
+	// condition of the loop
+	condition string
+}
+
+// Graph is the control flow graph of a function, vertices are identified by the block id
+type Graph = graph.Graph[int, *Block]
+
+func blockHash(b *Block) int {
+	return b.ID
+}
+
 type cfgParser struct {
-	g        graph.Graph[int, int]
+	g        Graph
+	source   []byte
 	counter  int
 	startRef int
 	endRef   int
+	// reference of the block which is still open for straight-line statements, -1 if none
+	openRef int
+	// vertices with at least one incoming edge
+	entered map[int]bool
+	// enclosing loops and switch statements, the innermost is the last one
+	jumps []jumpTarget
 }
 
-// generates a control flow graph based on a tree-sitter node (usually a function body)
-func Create(node *sitter.Node) graph.Graph[int, int] {
+// generates a control flow graph based on a tree-sitter node (usually a function body),
+// the source code is used for extracting the conditions of branches
+func Create(node *sitter.Node, source []byte) Graph {
 	cp := &cfgParser{
-		g:       graph.New(graph.IntHash, graph.Directed()),
+		g:       graph.New(blockHash, graph.Directed()),
+		source:  source,
 		counter: -1,
+		openRef: -1,
+		entered: map[int]bool{},
 	}
 
 	// start and endpoint
-	cp.startRef = cp.addVertex("start", "lightgreen")
-	cp.endRef = cp.addVertex("end", "crimson")
+	cp.startRef = cp.addVertex("start", "lightgreen", nil)
+	cp.endRef = cp.addVertex("end", "crimson", nil)
 
 	// handle the (function) body
-	prevRef := cp.blockToGraph(node, cp.startRef)
+	prev := cp.blockToGraph(node, flow{ref: cp.startRef})
 
-	cp.addEdge(prevRef, cp.endRef)
+	cp.connect(prev, cp.endRef)
 
 	return cp.g
 }
 
 // handles a single node
-func (cp *cfgParser) nodeToGraph(node *sitter.Node, prevRef int) int {
+func (cp *cfgParser) nodeToGraph(node *sitter.Node, prev flow) flow {
 	if node == nil {
-		return prevRef
+		return prev
 	}
 
 	switch node.Type() {
 	case "if_statement":
-		return cp.ifToGraph(node, prevRef)
+		return cp.ifToGraph(node, prev)
 	case "else_clause":
 		// use the first child should be "if_statement" or "statement_block"
-		return cp.nodeToGraph(node.NamedChild(0), prevRef)
+		return cp.nodeToGraph(node.NamedChild(0), prev)
 	case "switch_expression", "switch_statement":
 		switchBlock := node.ChildByFieldName("body")
-		return cp.switchToGraph(switchBlock, prevRef)
+		return cp.switchToGraph(switchBlock, prev)
 	case "expression_switch_statement":
-		return cp.switchToGraph(node, prevRef)
+		return cp.switchToGraph(node, prev)
 	case "do_statement":
-		return cp.doToGraph(node, prevRef)
+		return cp.doToGraph(node, prev)
 	case "while_statement":
-		return cp.whileToGraph(node, prevRef)
+		return cp.whileToGraph(node, prev)
 	case "for_statement":
-		return cp.forToGraph(node, prevRef)
+		return cp.forToGraph(node, prev)
 	case "block":
-		return cp.blockToGraph(node, prevRef)
+		return cp.blockToGraph(node, prev)
 	case "return_statement":
-		return cp.returnToGraph(node, prevRef)
+		return cp.returnToGraph(node, prev)
+	case "break_statement":
+		return cp.breakToGraph(prev)
+	case "continue_statement":
+		return cp.continueToGraph(prev)
+	case "try_statement":
+		return cp.tryToGraph(node, prev)
 
 	// ignore these nodes
 	case "expression_list":
-		return prevRef
+		return prev
 	default:
 		slog.Info("graph: unknown node type", "type", node.Type())
-		return cp.unknownToGraph(node, prevRef)
+		return cp.unknownToGraph(node, prev)
 	}
 }
 
-// iterates over all childs of a given block (eg. function body, if/else body, ...)
-func (cp *cfgParser) blockToGraph(block *sitter.Node, prevRef int) int {
+// iterates over all childs of a given block (eg. function body, if/else body, ...), an empty block returns
+// the given flow, so the kind of the edge is kept for the next vertex
+func (cp *cfgParser) blockToGraph(block *sitter.Node, prev flow) flow {
 	for i := 0; i < int(block.NamedChildCount()); i++ {
 		child := block.NamedChild(i)
 		switch child.Type() {
-		case "switch_label", "break_statement":
+		case "switch_label":
 			// ignore these labels
 		default:
-			prevRef = cp.nodeToGraph(child, prevRef)
+			prev = cp.nodeToGraph(child, prev)
 		}
 	}
-	return prevRef
+	return prev
 }
 
-func (cp *cfgParser) doToGraph(doStatement *sitter.Node, prevRef int) int {
-	startRef := cp.addVertex("do_start", "cyan")
-	cp.addEdge(prevRef, startRef)
+func (cp *cfgParser) doToGraph(doStatement *sitter.Node, prev flow) flow {
+	startRef := cp.addVertex("do_start", "cyan", doStatement)
+	cp.setCondition(startRef, doStatement)
+	cp.connect(prev, startRef)
 
 	// create end node and connect it with the start node
-	endRef := cp.addVertex("do_end", "cyan3")
+	endRef := cp.addVertex("do_end", "cyan3", doStatement)
 
-	blockRef := cp.blockToGraph(doStatement.ChildByFieldName("body"), startRef)
+	condition := cp.condition(doStatement)
+	cp.jumps = append(cp.jumps, jumpTarget{breakRef: endRef, continueRef: startRef, condition: condition})
+	block := cp.blockToGraph(doStatement.ChildByFieldName("body"), flow{ref: startRef})
+	cp.jumps = cp.jumps[:len(cp.jumps)-1]
 
 	// connect the last node of the block with the start and end nodes
-	cp.addEdge(blockRef, startRef)
-	cp.addEdge(blockRef, endRef)
+	cp.connect(flow{ref: block.ref, kind: EdgeLoopBack, condition: condition}, startRef)
+	cp.connect(flow{ref: block.ref, kind: EdgeFalse, condition: condition}, endRef)
 
-	return endRef
+	return cp.leave(endRef)
 }
 
-func (cp *cfgParser) whileToGraph(whileStatement *sitter.Node, prevRef int) int {
-	startRef := cp.addVertex("while_start", "cyan")
-	cp.addEdge(prevRef, startRef)
+func (cp *cfgParser) whileToGraph(whileStatement *sitter.Node, prev flow) flow {
+	startRef := cp.addVertex("while_start", "cyan", whileStatement)
+	cp.setCondition(startRef, whileStatement)
+	cp.connect(prev, startRef)
 
 	// create end node and connect it with the start node
-	endRef := cp.addVertex("while_end", "cyan3")
-	cp.addEdge(startRef, endRef)
+	endRef := cp.addVertex("while_end", "cyan3", whileStatement)
+	condition := cp.condition(whileStatement)
+	cp.connect(flow{ref: startRef, kind: EdgeFalse, condition: condition}, endRef)
 
-	blockRef := cp.blockToGraph(whileStatement.ChildByFieldName("body"), startRef)
+	cp.jumps = append(cp.jumps, jumpTarget{breakRef: endRef, continueRef: startRef, condition: condition})
+	block := cp.blockToGraph(whileStatement.ChildByFieldName("body"), flow{ref: startRef, kind: EdgeTrue, condition: condition})
+	cp.jumps = cp.jumps[:len(cp.jumps)-1]
 
 	// connect the last node of the block with the start node
-	cp.addEdge(blockRef, startRef)
+	cp.connect(cp.loopBack(block, condition), startRef)
 
-	return endRef
+	return flow{ref: endRef}
 }
 
 // parses a for loop into the cfg
-func (cp *cfgParser) forToGraph(forStatement *sitter.Node, prevRef int) int {
+func (cp *cfgParser) forToGraph(forStatement *sitter.Node, prev flow) flow {
 	// create start node
-	startRef := cp.addVertex("for_start", "cyan")
-	cp.addEdge(prevRef, startRef)
+	startRef := cp.addVertex("for_start", "cyan", forStatement)
+	cp.setCondition(startRef, forStatement)
+	cp.connect(prev, startRef)
 
-	// create end node and connect it with the start node
-	endRef := cp.addVertex("for_end", "cyan3")
-	cp.addEdge(endRef, startRef)
+	// create end node and connect it with the start node, the loop is skipped if the condition is false
+	endRef := cp.addVertex("for_end", "cyan3", forStatement)
+	condition := cp.condition(forStatement)
+	cp.connect(flow{ref: startRef, kind: EdgeFalse, condition: condition}, endRef)
 
-	blockRef := cp.blockToGraph(forStatement.ChildByFieldName("body"), startRef)
+	cp.jumps = append(cp.jumps, jumpTarget{breakRef: endRef, continueRef: startRef, condition: condition})
+	block := cp.blockToGraph(forStatement.ChildByFieldName("body"), flow{ref: startRef, kind: EdgeTrue, condition: condition})
+	cp.jumps = cp.jumps[:len(cp.jumps)-1]
 
-	// connect the last node of the block with the end node
-	cp.addEdge(blockRef, endRef)
+	// connect the last node of the block with the start node
+	cp.connect(cp.loopBack(block, condition), startRef)
 
-	return endRef
+	return flow{ref: endRef}
+}
+
+// returns the flow back to the start of a loop, an empty body is the true edge looping back
+func (cp *cfgParser) loopBack(block flow, condition string) flow {
+	if block.kind != "" {
+		return block
+	}
+	return flow{ref: block.ref, kind: EdgeLoopBack, condition: condition}
 }
 
 // parses switch statement into the cfg
-func (cp *cfgParser) switchToGraph(switchStatement *sitter.Node, prevRef int) int {
+func (cp *cfgParser) switchToGraph(switchStatement *sitter.Node, prev flow) flow {
 	// create start node and connect it with the previous one
-	startRef := cp.addVertex("switch_start", "cyan")
-	cp.addEdge(prevRef, startRef)
+	startRef := cp.addVertex("switch_start", "cyan", switchStatement)
+	if t := switchStatement.Type(); t == "switch_block" || t == "switch_body" {
+		// java/javascript: the condition is part of the parent statement
+		cp.setCondition(startRef, switchStatement.Parent())
+	} else {
+		cp.setCondition(startRef, switchStatement)
+	}
+	cp.connect(prev, startRef)
 
 	// create end node
-	endRef := cp.addVertex("switch_end", "cyan3")
+	endRef := cp.addVertex("switch_end", "cyan3", switchStatement)
 
 	defaultCase := false
+	cp.jumps = append(cp.jumps, jumpTarget{breakRef: endRef, continueRef: -1})
 
 	// iterate over the different cases
 	for i := 0; i < int(switchStatement.NamedChildCount()); i++ {
@@ -154,86 +276,301 @@ func (cp *cfgParser) switchToGraph(switchStatement *sitter.Node, prevRef int) in
 		case "switch_block_statement_group":
 			// TODO check explicitly for "default"
 			// java: if the switch label has no child it has to be the default case
-			if helper.FirstChildByType(child, "switch_label").NamedChildCount() == 0 {
+			label := helper.FirstChildByType(child, "switch_label")
+			branch := flow{ref: startRef, kind: EdgeDefault}
+			if label.NamedChildCount() == 0 {
 				defaultCase = true
+			} else {
+				branch = flow{ref: startRef, kind: EdgeCase, condition: label.NamedChild(0).Content(cp.source)}
 			}
-			caseRef := cp.blockToGraph(child, startRef)
-			cp.addEdge(caseRef, endRef)
+			cp.connect(cp.blockToGraph(child, branch), endRef)
 
 		case "switch_case":
+			branch := flow{ref: startRef, kind: EdgeCase, condition: cp.content(child.ChildByFieldName("value"))}
 			// TODO is there a better way to access the case block?
-			caseRef := cp.blockToGraph(child.NamedChild(1), startRef)
-			cp.addEdge(caseRef, endRef)
+			cp.connect(cp.blockToGraph(child.NamedChild(1), branch), endRef)
 
 		case "default_case", "switch_default":
 			defaultCase = true
-			fallthrough
+			cp.connect(cp.blockToGraph(child, flow{ref: startRef, kind: EdgeDefault}), endRef)
+
 		case "expression_case":
-			caseRef := cp.blockToGraph(child, startRef)
-			cp.addEdge(caseRef, endRef)
+			branch := flow{ref: startRef, kind: EdgeCase, condition: cp.content(child.ChildByFieldName("value"))}
+			cp.connect(cp.blockToGraph(child, branch), endRef)
 		}
 
 	}
+	cp.jumps = cp.jumps[:len(cp.jumps)-1]
 
 	// if there is no default case, connect the start node with the end node
 	if !defaultCase {
-		cp.addEdge(startRef, endRef)
+		cp.connect(flow{ref: startRef, kind: EdgeDefault}, endRef)
 	}
 
-	return endRef
+	return cp.leave(endRef)
 }
 
 // parses if/elseif/else nodes into the cfg
-func (cp *cfgParser) ifToGraph(ifStatement *sitter.Node, prevRef int) int {
+func (cp *cfgParser) ifToGraph(ifStatement *sitter.Node, prev flow) flow {
 	// create node for "if" start
-	startRef := cp.addVertex("if_start", "cyan")
-	cp.addEdge(prevRef, startRef)
+	startRef := cp.addVertex("if_start", "cyan", ifStatement)
+	cp.setCondition(startRef, ifStatement)
+	cp.connect(prev, startRef)
 
 	// add node to end if
-	endRef := cp.addVertex("if_end", "cyan3")
+	endRef := cp.addVertex("if_end", "cyan3", ifStatement)
+
+	// parse the "if" and "else" paths, empty paths are connected with the end node directly
+	condition := cp.condition(ifStatement)
+	consequence := cp.nodeToGraph(ifStatement.ChildByFieldName("consequence"), flow{ref: startRef, kind: EdgeTrue, condition: condition})
+	cp.connect(consequence, endRef)
+	alternative := cp.nodeToGraph(ifStatement.ChildByFieldName("alternative"), flow{ref: startRef, kind: EdgeFalse, condition: condition})
+	cp.connect(alternative, endRef)
+
+	return cp.leave(endRef)
+}
+
+// parses try/catch/finally into the cfg, every catch clause is connected by an exception edge
+func (cp *cfgParser) tryToGraph(tryStatement *sitter.Node, prev flow) flow {
+	startRef := cp.addVertex("try_start", "cyan", tryStatement)
+	cp.connect(prev, startRef)
+
+	endRef := cp.addVertex("try_end", "cyan3", tryStatement)
+
+	body := cp.blockToGraph(tryStatement.ChildByFieldName("body"), flow{ref: startRef})
+	cp.connect(body, endRef)
+
+	var finally *sitter.Node
+	for i := 0; i < int(tryStatement.NamedChildCount()); i++ {
+		child := tryStatement.NamedChild(i)
+		switch child.Type() {
+		case "catch_clause":
+			// java: catch_formal_parameter, javascript: parameter field
+			param := child.ChildByFieldName("parameter")
+			if param == nil {
+				param = helper.FirstChildByType(child, "catch_formal_parameter")
+			}
+			branch := flow{ref: startRef, kind: EdgeException, condition: cp.content(param)}
+			cp.connect(cp.blockToGraph(child.ChildByFieldName("body"), branch), endRef)
+		case "finally_clause":
+			finally = child
+		}
+	}
 
-	// parse the "if" path
-	prevRef = cp.nodeToGraph(ifStatement.ChildByFieldName("consequence"), startRef)
-	cp.addEdge(prevRef, endRef)
-	prevRef = cp.nodeToGraph(ifStatement.ChildByFieldName("alternative"), startRef)
-	cp.addEdge(prevRef, endRef)
+	next := cp.leave(endRef)
+	if finally != nil {
+		body := finally.ChildByFieldName("body")
+		if body == nil {
+			// java: the block has no field name
+			body = helper.FirstChildByType(finally, "block")
+		}
+		if next == unreachable {
+			// every path returns, but the finally block is executed anyway
+			cp.blockToGraph(body, flow{ref: startRef})
+			return unreachable
+		}
+		return cp.blockToGraph(body, next)
+	}
 
-	return endRef
+	return next
 }
 
-// handle return statement
-func (cp *cfgParser) returnToGraph(node *sitter.Node, prevRef int) int {
-	ref := cp.addVertex("return", "red")
-	cp.addEdge(prevRef, ref)
-	return ref
+// handles return statements, the control flow continues at the end vertex
+func (cp *cfgParser) returnToGraph(node *sitter.Node, prev flow) flow {
+	ref := cp.addVertex("return", "red", node)
+	cp.connect(prev, ref)
+	cp.connect(flow{ref: ref}, cp.endRef)
+	return unreachable
 }
 
-// handles unknown nodes
-func (cp *cfgParser) unknownToGraph(node *sitter.Node, prevRef int) int {
-	ref := cp.addVertex(node.Type(), "azure")
-	cp.addEdge(prevRef, ref)
-	return ref
+// handles break statements, the control flow continues at the end of the innermost loop or switch statement.
+// Labels are not resolved.
+func (cp *cfgParser) breakToGraph(prev flow) flow {
+	if len(cp.jumps) == 0 {
+		return prev
+	}
+	cp.connect(prev, cp.jumps[len(cp.jumps)-1].breakRef)
+	return unreachable
 }
 
-// wrapper for adding edges
-func (cp *cfgParser) addEdge(start, end int) {
-	err := cp.g.AddEdge(start, end)
+// handles continue statements, the control flow continues at the start of the innermost loop.
+// Labels are not resolved.
+func (cp *cfgParser) continueToGraph(prev flow) flow {
+	for i := len(cp.jumps) - 1; i >= 0; i-- {
+		if target := cp.jumps[i]; target.continueRef != -1 {
+			cp.connect(cp.loopBack(prev, target.condition), target.continueRef)
+			return unreachable
+		}
+	}
+	return prev
+}
+
+// handles unknown nodes, consecutive statements are collapsed into a single block
+func (cp *cfgParser) unknownToGraph(node *sitter.Node, prev flow) flow {
+	if prev.ref >= 0 && prev.ref == cp.openRef && prev.kind == "" {
+		cp.appendStatement(prev.ref, node)
+		return prev
+	}
+
+	ref := cp.addVertex("block", "azure", node)
+	cp.connect(prev, ref)
+	cp.openRef = ref
+	return flow{ref: ref}
+}
+
+// adds a statement to an existing block and extends its source span
+func (cp *cfgParser) appendStatement(ref int, node *sitter.Node) {
+	block, props, err := cp.g.VertexWithProperties(ref)
+	if err != nil {
+		slog.Warn("unable to find block in graph", "ref", ref)
+		return
+	}
+	block.Statements = append(block.Statements, newStatement(node))
+	block.EndByte = node.EndByte()
+	block.EndLine = node.EndPoint().Row + 1
+	props.Attributes["label"] = block.label()
+}
+
+// returns the flow leaving the end vertex of a control structure, an end vertex without any incoming edge
+// (eg. every branch returns) is removed
+func (cp *cfgParser) leave(endRef int) flow {
+	if cp.entered[endRef] {
+		return flow{ref: endRef}
+	}
+	if err := cp.g.RemoveVertex(endRef); err != nil {
+		slog.Warn("unable to remove node from graph", "ref", endRef)
+	}
+	return unreachable
+}
+
+// connects the open end of the control flow with a vertex, the kind and condition of the flow are attached to
+// the edge. An existing edge (eg. of an empty branch) is not replaced, the flow gets its own join vertex.
+// Unreachable flows are not connected.
+func (cp *cfgParser) connect(from flow, to int) {
+	if from == unreachable {
+		return
+	}
+	if _, err := cp.g.Edge(from.ref, to); err == nil {
+		joinRef := cp.addVertex("join", "azure", nil)
+		cp.connect(from, joinRef)
+		from = flow{ref: joinRef}
+	}
+
+	attributes := map[string]string{}
+	if from.kind != "" {
+		attributes["label"] = from.kind.label(from.condition)
+		attributes["kind"] = string(from.kind)
+		if from.condition != "" {
+			attributes["condition"] = from.condition
+		}
+	}
+
+	err := cp.g.AddEdge(from.ref, to, graph.EdgeAttributes(attributes))
 	if err != nil {
-		slog.Warn("unable to add edge to graph", "start", start, "end", end)
+		slog.Warn("unable to add edge to graph", "start", from.ref, "end", to)
+		return
 	}
+	cp.entered[to] = true
 }
 
-// wrapper for adding nodes
-func (cp *cfgParser) addVertex(label string, color string) int {
+// wrapper for adding nodes, the (optional) node defines the source span of the vertex
+func (cp *cfgParser) addVertex(kind string, color string, node *sitter.Node) int {
 	cp.counter++
-	err := cp.g.AddVertex(cp.counter, graph.VertexAttributes(map[string]string{
-		"label":     fmt.Sprintf("%d: %s", cp.counter, label),
+	// every new vertex closes the currently open block
+	cp.openRef = -1
+
+	block := &Block{ID: cp.counter, Kind: kind}
+	if node != nil {
+		block.StartByte = node.StartByte()
+		block.EndByte = node.EndByte()
+		block.StartLine = node.StartPoint().Row + 1
+		block.EndLine = node.EndPoint().Row + 1
+		if kind == "block" || kind == "return" {
+			block.Statements = []*Statement{newStatement(node)}
+		}
+	}
+
+	err := cp.g.AddVertex(block, graph.VertexAttributes(map[string]string{
+		"label":     block.label(),
 		"style":     "filled, solid",
 		"color":     "black",
 		"fillcolor": color,
 	}))
 	if err != nil {
-		slog.Warn("unable to add node to graph", "label", label)
+		slog.Warn("unable to add node to graph", "kind", kind)
 	}
 	return cp.counter
 }
+
+// stores the source code of the condition of a control statement in the block
+func (cp *cfgParser) setCondition(ref int, statement *sitter.Node) {
+	condition := cp.condition(statement)
+	if condition == "" {
+		return
+	}
+	block, props, err := cp.g.VertexWithProperties(ref)
+	if err != nil {
+		slog.Warn("unable to find block in graph", "ref", ref)
+		return
+	}
+	block.Condition = condition
+	props.Attributes["label"] = block.label()
+}
+
+// returns the source code of the condition of a control statement
+func (cp *cfgParser) condition(statement *sitter.Node) string {
+	return cp.content(conditionNode(statement))
+}
+
+// returns the source code of a node, empty if the source is not available
+func (cp *cfgParser) content(node *sitter.Node) string {
+	if node == nil || cp.source == nil {
+		return ""
+	}
+	return node.Content(cp.source)
+}
+
+// returns the node holding the condition of a control statement
+func conditionNode(statement *sitter.Node) *sitter.Node {
+	condition := statement.ChildByFieldName("condition")
+	if condition == nil {
+		// go: switch value
+		condition = statement.ChildByFieldName("value")
+	}
+	if condition == nil {
+		// go: for clauses are not accessible by field names
+		first := statement.NamedChild(0)
+		if first != nil && first.Type() != "block" && first.Type() != "comment" {
+			condition = first
+		}
+	}
+	// java/javascript: strip the parentheses
+	if condition != nil && condition.Type() == "parenthesized_expression" && condition.NamedChildCount() == 1 {
+		condition = condition.NamedChild(0)
+	}
+	return condition
+}
+
+func newStatement(node *sitter.Node) *Statement {
+	return &Statement{
+		Type:      node.Type(),
+		StartByte: node.StartByte(),
+		EndByte:   node.EndByte(),
+		StartLine: node.StartPoint().Row + 1,
+		EndLine:   node.EndPoint().Row + 1,
+	}
+}
+
+// returns the label used for drawing the block
+func (b *Block) label() string {
+	label := fmt.Sprintf("%d: %s", b.ID, b.Kind)
+	if b.StartLine == b.EndLine && b.StartLine != 0 {
+		label += fmt.Sprintf(" (L%d)", b.StartLine)
+	} else if b.StartLine != 0 {
+		label += fmt.Sprintf(" (L%d-%d)", b.StartLine, b.EndLine)
+	}
+	if b.Condition != "" {
+		label += fmt.Sprintf(" [%s]", b.Condition)
+	}
+	return label
+}
@@ -3,7 +3,6 @@ package cfg_test
 import (
 	"testing"
 
-	"github.com/jochil/gcs/pkg/helper"
 	"github.com/jochil/gcs/pkg/parser"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -31,7 +30,7 @@ func TestGraph(t *testing.T) {
 			wantEdges: 6,
 			wantNodes: 6,
 			nodes:     []node{{2, "if_start"}, {3, "if_end"}},
-			edges:     []edge{{2, 4}, {4, 3}, {2, 3}},
+			edges:     []edge{{2, 4}, {4, 1}, {2, 3}, {3, 5}, {5, 1}},
 		},
 		"go_if_else": {
 			path:      "testdata/cyclo/golang/c.go",
@@ -59,7 +58,36 @@ func TestGraph(t *testing.T) {
 			wantEdges: 5,
 			wantNodes: 5,
 			nodes:     []node{{2, "for_start"}, {3, "for_end"}},
-			edges:     []edge{{2, 4}, {4, 3}, {3, 2}},
+			edges:     []edge{{2, 3}, {2, 4}, {4, 2}, {3, 1}},
+		},
+		"go_basic_blocks": {
+			path:      "testdata/cyclo/golang/g.go",
+			wantEdges: 7,
+			wantNodes: 7,
+			nodes:     []node{{2, "block"}, {3, "if_start"}, {4, "if_end"}, {5, "block"}, {6, "return"}},
+			edges:     []edge{{0, 2}, {2, 3}, {3, 5}, {5, 4}, {3, 4}, {4, 6}, {6, 1}},
+		},
+		"go_empty_branches": {
+			path:      "testdata/cyclo/golang/i.go",
+			wantEdges: 11,
+			wantNodes: 9,
+			nodes:     []node{{2, "if_start"}, {3, "if_end"}, {4, "join"}, {5, "switch_start"}, {7, "join"}, {8, "join"}},
+			edges:     []edge{{2, 3}, {2, 4}, {4, 3}, {5, 6}, {5, 7}, {7, 6}, {5, 8}, {8, 6}},
+		},
+		"go_early_returns": {
+			path:      "testdata/cyclo/golang/j.go",
+			wantEdges: 10,
+			wantNodes: 9,
+			nodes:     []node{{4, "return"}, {7, "return"}, {8, "return"}},
+			edges:     []edge{{2, 4}, {4, 1}, {5, 7}, {7, 1}, {6, 8}, {8, 1}},
+		},
+		"go_jumps": {
+			path:      "testdata/cyclo/golang/k.go",
+			wantEdges: 20,
+			wantNodes: 17,
+			nodes:     []node{{7, "block"}, {10, "block"}, {15, "block"}},
+			// continue, break and return
+			edges: []edge{{5, 3}, {8, 4}, {14, 1}, {7, 6}, {10, 9}, {15, 13}},
 		},
 		"java_no_control": {path: "testdata/cyclo/java/NoControl.java", wantEdges: 3, wantNodes: 4, edges: []edge{{0, 2}, {2, 3}, {3, 1}}},
 		"java_simple_if": {
@@ -67,7 +95,7 @@ func TestGraph(t *testing.T) {
 			wantEdges: 6,
 			wantNodes: 6,
 			nodes:     []node{{2, "if_start"}, {3, "if_end"}},
-			edges:     []edge{{2, 4}, {4, 3}, {2, 3}},
+			edges:     []edge{{2, 4}, {4, 1}, {2, 3}, {3, 5}, {5, 1}},
 		},
 		"java_if_else": {
 			path:      "testdata/cyclo/java/IfElse.java",
@@ -95,7 +123,7 @@ func TestGraph(t *testing.T) {
 			wantEdges: 5,
 			wantNodes: 5,
 			nodes:     []node{{2, "for_start"}, {3, "for_end"}},
-			edges:     []edge{{2, 4}, {4, 3}, {3, 2}},
+			edges:     []edge{{2, 3}, {2, 4}, {4, 2}, {3, 1}},
 		},
 		"java_while": {
 			path:      "testdata/cyclo/java/While.java",
@@ -111,6 +139,13 @@ func TestGraph(t *testing.T) {
 			nodes:     []node{{3, "do_start"}, {4, "do_end"}},
 			edges:     []edge{{3, 5}, {5, 3}, {5, 4}, {4, 1}},
 		},
+		"java_try": {
+			path:      "testdata/cyclo/java/Try.java",
+			wantEdges: 9,
+			wantNodes: 8,
+			nodes:     []node{{2, "try_start"}, {3, "try_end"}},
+			edges:     []edge{{2, 4}, {4, 3}, {2, 5}, {5, 3}, {2, 6}, {6, 3}, {3, 7}, {7, 1}},
+		},
 		"javascript_no_control": {path: "testdata/cyclo/javascript/noControl.js", wantEdges: 3, wantNodes: 4, edges: []edge{{0, 2}, {2, 3}, {3, 1}}},
 		"javascript_simple_if": {
 			path:      "testdata/cyclo/javascript/if.js",
@@ -131,7 +166,7 @@ func TestGraph(t *testing.T) {
 			wantEdges: 5,
 			wantNodes: 5,
 			nodes:     []node{{2, "for_start"}, {3, "for_end"}},
-			edges:     []edge{{2, 4}, {4, 3}, {3, 2}},
+			edges:     []edge{{2, 3}, {2, 4}, {4, 2}, {3, 1}},
 		},
 		"javascript_switch_no_default": {
 			path:      "testdata/cyclo/javascript/switch.js",
@@ -161,14 +196,22 @@ func TestGraph(t *testing.T) {
 			nodes:     []node{{3, "do_start"}, {4, "do_end"}},
 			edges:     []edge{{3, 5}, {5, 3}, {5, 4}, {4, 1}},
 		},
+		"javascript_try": {
+			path:      "testdata/cyclo/javascript/try.js",
+			wantEdges: 6,
+			wantNodes: 6,
+			nodes:     []node{{2, "try_start"}, {3, "try_end"}},
+			edges:     []edge{{2, 4}, {4, 3}, {2, 5}, {5, 3}, {3, 1}},
+		},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			candidates := parser.NewParser(helper.GuessLanguage(tc.path)).Parse()
+			candidates, err := parser.ParseFile(tc.path)
+			require.NoError(t, err)
 			candidates.CalcScore()
 			cfg := candidates[0].ControlFlowGraph
-			//candidates[0].SaveGraph()
+			//candidates[0].SaveGraph("../../.draw", cfg.FormatDOT)
 
 			edges, err := cfg.Size()
 			require.NoError(t, err)
@@ -191,3 +234,87 @@ func TestGraph(t *testing.T) {
 		})
 	}
 }
+
+func TestGraph_Blocks(t *testing.T) {
+	candidates, err := parser.ParseFile("testdata/cyclo/golang/g.go")
+	require.NoError(t, err)
+	candidates.CalcScore()
+	g := candidates[0].ControlFlowGraph
+
+	tests := map[string]struct {
+		ref        int
+		kind       string
+		startLine  uint32
+		endLine    uint32
+		statements []string
+	}{
+		"start":      {ref: 0, kind: "start"},
+		"first":      {ref: 2, kind: "block", startLine: 7, endLine: 9, statements: []string{"short_var_declaration", "call_expression", "inc_statement"}},
+		"if_start":   {ref: 3, kind: "if_start", startLine: 10, endLine: 13},
+		"consequent": {ref: 5, kind: "block", startLine: 11, endLine: 12, statements: []string{"call_expression", "assignment_statement"}},
+		"return":     {ref: 6, kind: "return", startLine: 14, endLine: 14, statements: []string{"return_statement"}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			block, err := g.Vertex(tc.ref)
+			require.NoError(t, err)
+			assert.Equal(t, tc.kind, block.Kind)
+			assert.Equal(t, tc.startLine, block.StartLine, "wrong start line")
+			assert.Equal(t, tc.endLine, block.EndLine, "wrong end line")
+
+			statements := []string{}
+			for _, s := range block.Statements {
+				statements = append(statements, s.Type)
+			}
+			assert.ElementsMatch(t, tc.statements, statements)
+		})
+	}
+}
+
+func TestGraph_EdgeLabels(t *testing.T) {
+	tests := map[string]struct {
+		path      string
+		s         int
+		e         int
+		kind      string
+		condition string
+	}{
+		"go_if_true":              {path: "testdata/cyclo/golang/b.go", s: 2, e: 4, kind: "true", condition: "a > 0"},
+		"go_if_false":             {path: "testdata/cyclo/golang/b.go", s: 2, e: 3, kind: "false", condition: "a > 0"},
+		"go_case":                 {path: "testdata/cyclo/golang/e.go", s: 2, e: 5, kind: "case", condition: "2"},
+		"go_default":              {path: "testdata/cyclo/golang/e.go", s: 2, e: 6, kind: "default"},
+		"go_implicit_default":     {path: "testdata/cyclo/golang/d.go", s: 2, e: 3, kind: "default"},
+		"go_for_false":            {path: "testdata/cyclo/golang/f.go", s: 2, e: 3, kind: "false", condition: "i := 0; i < 3; i++"},
+		"go_for_loop_back":        {path: "testdata/cyclo/golang/f.go", s: 4, e: 2, kind: "loop-back", condition: "i := 0; i < 3; i++"},
+		"go_empty_if_true":        {path: "testdata/cyclo/golang/i.go", s: 2, e: 3, kind: "true", condition: "a > 0"},
+		"go_empty_if_false":       {path: "testdata/cyclo/golang/i.go", s: 2, e: 4, kind: "false", condition: "a > 0"},
+		"go_empty_case":           {path: "testdata/cyclo/golang/i.go", s: 5, e: 7, kind: "case", condition: "2"},
+		"go_empty_default":        {path: "testdata/cyclo/golang/i.go", s: 5, e: 8, kind: "default"},
+		"go_continue":             {path: "testdata/cyclo/golang/k.go", s: 5, e: 3, kind: "true", condition: "v < 0"},
+		"go_break":                {path: "testdata/cyclo/golang/k.go", s: 8, e: 4, kind: "true", condition: "v > 100"},
+		"java_while_true":         {path: "testdata/cyclo/java/While.java", s: 3, e: 5, kind: "true", condition: "i < 5"},
+		"java_while_false":        {path: "testdata/cyclo/java/While.java", s: 3, e: 4, kind: "false", condition: "i < 5"},
+		"java_while_loop_back":    {path: "testdata/cyclo/java/While.java", s: 5, e: 3, kind: "loop-back", condition: "i < 5"},
+		"java_do_false":           {path: "testdata/cyclo/java/Do.java", s: 5, e: 4, kind: "false", condition: "i < 5"},
+		"java_case":               {path: "testdata/cyclo/java/SwitchDefault.java", s: 2, e: 4, kind: "case", condition: "1"},
+		"java_exception":          {path: "testdata/cyclo/java/Try.java", s: 2, e: 6, kind: "exception", condition: "IOException e"},
+		"javascript_case":         {path: "testdata/cyclo/javascript/switchDefault.js", s: 2, e: 5, kind: "case", condition: "2"},
+		"javascript_default":      {path: "testdata/cyclo/javascript/switchDefault.js", s: 2, e: 6, kind: "default"},
+		"javascript_exception":    {path: "testdata/cyclo/javascript/try.js", s: 2, e: 5, kind: "exception", condition: "e"},
+		"javascript_else_if_true": {path: "testdata/cyclo/javascript/ifElse.js", s: 5, e: 7, kind: "true", condition: "a == 5"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			candidates, err := parser.ParseFile(tc.path)
+			require.NoError(t, err)
+			candidates.CalcScore()
+
+			e, err := candidates[0].ControlFlowGraph.Edge(tc.s, tc.e)
+			require.NoError(t, err, "missing edge %d -> %d", tc.s, tc.e)
+			assert.Equal(t, tc.kind, e.Properties.Attributes["kind"], "wrong edge kind")
+			assert.Equal(t, tc.condition, e.Properties.Attributes["condition"], "wrong condition")
+		})
+	}
+}
@@ -0,0 +1,354 @@
+package cfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/dominikbraun/graph"
+)
+
+// Format describes an output format for exporting a control flow graph
+type Format string
+
+const (
+	FormatDOT     Format = "dot"
+	FormatMermaid Format = "mermaid"
+	FormatJSON    Format = "json"
+	FormatSVG     Format = "svg"
+)
+
+// Formats lists all supported export formats
+var Formats = []Format{FormatDOT, FormatMermaid, FormatJSON, FormatSVG}
+
+// Ext returns the file extension used for the format
+func (f Format) Ext() string {
+	switch f {
+	case FormatDOT:
+		return ".gv"
+	case FormatMermaid:
+		return ".mmd"
+	}
+	return "." + string(f)
+}
+
+// ParseFormat validates a format given as string (eg. by a command line flag)
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(s))
+	if !slices.Contains(Formats, f) {
+		return "", fmt.Errorf("unsupported graph format: %s", s)
+	}
+	return f, nil
+}
+
+// Node is the exported representation of a vertex
+type Node struct {
+	*Block
+	Attributes map[string]string `json:"attributes"`
+}
+
+// Edge is the exported representation of an edge
+type Edge struct {
+	Source     int               `json:"source"`
+	Target     int               `json:"target"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// Export writes the graph in the given format
+func Export(g Graph, w io.Writer, format Format) error {
+	nodes, edges, err := collect(g)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatDOT:
+		return writeDOT(w, nodes, edges)
+	case FormatMermaid:
+		return writeMermaid(w, nodes, edges)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Nodes []*Node `json:"nodes"`
+			Edges []*Edge `json:"edges"`
+		}{nodes, edges})
+	case FormatSVG:
+		return writeSVG(w, nodes, edges)
+	}
+	return fmt.Errorf("unsupported graph format: %s", format)
+}
+
+// collects all nodes and edges ordered by their ids, so the output is stable
+func collect(g Graph) ([]*Node, []*Edge, error) {
+	adjacencyMap, err := g.AdjacencyMap()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes := []*Node{}
+	for ref := range adjacencyMap {
+		block, props, err := g.VertexWithProperties(ref)
+		if err != nil {
+			return nil, nil, err
+		}
+		nodes = append(nodes, &Node{Block: block, Attributes: props.Attributes})
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].ID < nodes[j].ID
+	})
+
+	edges := []*Edge{}
+	for source, targets := range adjacencyMap {
+		for target, e := range targets {
+			edges = append(edges, &Edge{Source: source, Target: target, Attributes: edgeAttributes(e)})
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Source == edges[j].Source {
+			return edges[i].Target < edges[j].Target
+		}
+		return edges[i].Source < edges[j].Source
+	})
+
+	return nodes, edges, nil
+}
+
+func edgeAttributes(e graph.Edge[int]) map[string]string {
+	if e.Properties.Attributes == nil {
+		return map[string]string{}
+	}
+	return e.Properties.Attributes
+}
+
+// writes the attributes in a stable order as DOT attribute list
+func dotAttributes(attributes map[string]string) string {
+	keys := []string{}
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := []string{}
+	for _, k := range keys {
+		attrs = append(attrs, fmt.Sprintf("%s=%q", k, attributes[k]))
+	}
+	return strings.Join(attrs, ", ")
+}
+
+func writeDOT(w io.Writer, nodes []*Node, edges []*Edge) error {
+	out := "strict digraph {\n"
+	for _, n := range nodes {
+		out += fmt.Sprintf("\t%d [ %s ];\n", n.ID, dotAttributes(n.Attributes))
+	}
+	for _, e := range edges {
+		if len(e.Attributes) == 0 {
+			out += fmt.Sprintf("\t%d -> %d;\n", e.Source, e.Target)
+		} else {
+			out += fmt.Sprintf("\t%d -> %d [ %s ];\n", e.Source, e.Target, dotAttributes(e.Attributes))
+		}
+	}
+	out += "}\n"
+
+	_, err := io.WriteString(w, out)
+	return err
+}
+
+func writeMermaid(w io.Writer, nodes []*Node, edges []*Edge) error {
+	// mermaid uses html entities for escaping inside of labels
+	escape := func(s string) string {
+		return strings.ReplaceAll(s, `"`, "#quot;")
+	}
+
+	out := "flowchart TD\n"
+	for _, n := range nodes {
+		out += fmt.Sprintf("\tn%d[\"%s\"]\n", n.ID, escape(n.Attributes["label"]))
+	}
+	for _, e := range edges {
+		if label := e.Attributes["label"]; label != "" {
+			out += fmt.Sprintf("\tn%d -->|\"%s\"| n%d\n", e.Source, escape(label), e.Target)
+		} else {
+			out += fmt.Sprintf("\tn%d --> n%d\n", e.Source, e.Target)
+		}
+	}
+	for _, n := range nodes {
+		if color := n.Attributes["fillcolor"]; color != "" {
+			out += fmt.Sprintf("\tstyle n%d fill:%s\n", n.ID, color)
+		}
+	}
+
+	_, err := io.WriteString(w, out)
+	return err
+}
+
+const (
+	svgNodeHeight = 30
+	svgLayerGap   = 50
+	svgNodeGap    = 30
+	svgCharWidth  = 7
+	svgPadding    = 20
+)
+
+type svgBox struct {
+	x, y, width int
+}
+
+// renders the graph as svg using a simple layered layout, vertices are placed in rows
+// based on their longest distance from the start vertex (ignoring back edges of loops)
+func writeSVG(w io.Writer, nodes []*Node, edges []*Edge) error {
+	layers := layer(nodes, edges)
+
+	rows := map[int][]*Node{}
+	maxLayer := 0
+	for _, n := range nodes {
+		l := layers[n.ID]
+		rows[l] = append(rows[l], n)
+		if l > maxLayer {
+			maxLayer = l
+		}
+	}
+
+	rowWidth := func(row []*Node) int {
+		width := 0
+		for i, n := range row {
+			if i > 0 {
+				width += svgNodeGap
+			}
+			width += nodeWidth(n)
+		}
+		return width
+	}
+
+	canvasWidth := 0
+	for _, row := range rows {
+		if width := rowWidth(row); width > canvasWidth {
+			canvasWidth = width
+		}
+	}
+	// reserve some space on the right side for back edges
+	canvasWidth += 4 * svgPadding
+	canvasHeight := (maxLayer+1)*(svgNodeHeight+svgLayerGap) - svgLayerGap + 2*svgPadding
+
+	boxes := map[int]svgBox{}
+	for l := 0; l <= maxLayer; l++ {
+		row := rows[l]
+		x := svgPadding + (canvasWidth-4*svgPadding-rowWidth(row))/2
+		y := svgPadding + l*(svgNodeHeight+svgLayerGap)
+		for _, n := range row {
+			boxes[n.ID] = svgBox{x: x, y: y, width: nodeWidth(n)}
+			x += nodeWidth(n) + svgNodeGap
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace" font-size="12">`+"\n",
+		canvasWidth, canvasHeight, canvasWidth, canvasHeight)
+	sb.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z"/></marker></defs>` + "\n")
+
+	for _, e := range edges {
+		s, t := boxes[e.Source], boxes[e.Target]
+		var labelX, labelY int
+		if layers[e.Target] > layers[e.Source] {
+			x1, y1 := s.x+s.width/2, s.y+svgNodeHeight
+			x2, y2 := t.x+t.width/2, t.y
+			fmt.Fprintf(&sb, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black" marker-end="url(#arrow)"/>`+"\n", x1, y1, x2, y2)
+			labelX, labelY = (x1+x2)/2+4, (y1+y2)/2
+		} else {
+			// back edge: leave on the right side and curve around
+			x1, y1 := s.x+s.width, s.y+svgNodeHeight/2
+			x2, y2 := t.x+t.width, t.y+svgNodeHeight/2
+			cx := max(x1, x2) + 2*svgPadding
+			fmt.Fprintf(&sb, `<path d="M %d %d C %d %d, %d %d, %d %d" fill="none" stroke="black" marker-end="url(#arrow)"/>`+"\n", x1, y1, cx, y1, cx, y2, x2, y2)
+			labelX, labelY = cx-svgPadding, (y1+y2)/2
+		}
+		if label := e.Attributes["label"]; label != "" {
+			fmt.Fprintf(&sb, `<text x="%d" y="%d">%s</text>`+"\n", labelX, labelY, html.EscapeString(label))
+		}
+	}
+
+	for _, n := range nodes {
+		b := boxes[n.ID]
+		fill := n.Attributes["fillcolor"]
+		if fill == "" {
+			fill = "white"
+		}
+		fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="%d" rx="4" fill="%s" stroke="black"/>`+"\n", b.x, b.y, b.width, svgNodeHeight, fill)
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" text-anchor="middle" dominant-baseline="middle">%s</text>`+"\n",
+			b.x+b.width/2, b.y+svgNodeHeight/2, html.EscapeString(n.Attributes["label"]))
+	}
+	sb.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func nodeWidth(n *Node) int {
+	return len(n.Attributes["label"])*svgCharWidth + svgPadding
+}
+
+// assigns every vertex to a layer using the longest path from the start vertex
+func layer(nodes []*Node, edges []*Edge) map[int]int {
+	successors := map[int][]int{}
+	for _, e := range edges {
+		successors[e.Source] = append(successors[e.Source], e.Target)
+	}
+
+	// find back edges with a depth first search
+	back := map[[2]int]bool{}
+	state := map[int]int{} // 0: unvisited, 1: on stack, 2: done
+	var visit func(int)
+	visit = func(v int) {
+		state[v] = 1
+		for _, s := range successors[v] {
+			switch state[s] {
+			case 0:
+				visit(s)
+			case 1:
+				back[[2]int{v, s}] = true
+			}
+		}
+		state[v] = 2
+	}
+	for _, n := range nodes {
+		if state[n.ID] == 0 {
+			visit(n.ID)
+		}
+	}
+
+	// longest path layering over the remaining acyclic graph
+	inDegree := map[int]int{}
+	for _, e := range edges {
+		if !back[[2]int{e.Source, e.Target}] {
+			inDegree[e.Target]++
+		}
+	}
+	queue := []int{}
+	for _, n := range nodes {
+		if inDegree[n.ID] == 0 {
+			queue = append(queue, n.ID)
+		}
+	}
+
+	layers := map[int]int{}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, s := range successors[v] {
+			if back[[2]int{v, s}] {
+				continue
+			}
+			if layers[v]+1 > layers[s] {
+				layers[s] = layers[v] + 1
+			}
+			inDegree[s]--
+			if inDegree[s] == 0 {
+				queue = append(queue, s)
+			}
+		}
+	}
+	return layers
+}
@@ -0,0 +1,83 @@
+package cfg_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/jochil/gcs/pkg/cfg"
+	"github.com/jochil/gcs/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exportGraph(t *testing.T, path string, format cfg.Format) string {
+	t.Helper()
+	candidates, err := parser.ParseFile(path)
+	require.NoError(t, err)
+	candidates.CalcScore()
+
+	buf := &bytes.Buffer{}
+	err = cfg.Export(candidates[0].ControlFlowGraph, buf, format)
+	require.NoError(t, err)
+	return buf.String()
+}
+
+func TestExport_DOT(t *testing.T) {
+	out := exportGraph(t, "testdata/cyclo/golang/f.go", cfg.FormatDOT)
+	assert.Contains(t, out, "strict digraph {")
+	assert.Contains(t, out, `2 [ color="black", fillcolor="cyan", label="2: for_start (L6-8) [i := 0; i < 3; i++]", style="filled, solid" ];`)
+	assert.Contains(t, out, `4 -> 2 [ condition="i := 0; i < 3; i++", kind="loop-back", label="loop-back" ];`)
+	assert.Contains(t, out, `2 -> 3 [ condition="i := 0; i < 3; i++", kind="false", label="false" ];`)
+
+	// output has to be stable
+	assert.Equal(t, out, exportGraph(t, "testdata/cyclo/golang/f.go", cfg.FormatDOT))
+}
+
+func TestExport_Mermaid(t *testing.T) {
+	out := exportGraph(t, "testdata/cyclo/golang/f.go", cfg.FormatMermaid)
+	assert.Contains(t, out, "flowchart TD\n")
+	assert.Contains(t, out, `n4["4: block (L7)"]`)
+	assert.Contains(t, out, `n2 -->|"false"| n3`)
+	assert.Contains(t, out, `n4 -->|"loop-back"| n2`)
+}
+
+func TestExport_JSON(t *testing.T) {
+	out := exportGraph(t, "testdata/cyclo/golang/g.go", cfg.FormatJSON)
+
+	var result struct {
+		Nodes []struct {
+			ID         int               `json:"id"`
+			Kind       string            `json:"kind"`
+			StartLine  int               `json:"start_line"`
+			Statements []*cfg.Statement  `json:"statements"`
+			Attributes map[string]string `json:"attributes"`
+		} `json:"nodes"`
+		Edges []struct {
+			Source int `json:"source"`
+			Target int `json:"target"`
+		} `json:"edges"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.Len(t, result.Nodes, 7)
+	assert.Len(t, result.Edges, 7)
+	assert.Equal(t, "block", result.Nodes[2].Kind)
+	assert.Equal(t, 7, result.Nodes[2].StartLine)
+	assert.Len(t, result.Nodes[2].Statements, 3)
+}
+
+func TestExport_SVG(t *testing.T) {
+	out := exportGraph(t, "testdata/cyclo/golang/f.go", cfg.FormatSVG)
+	assert.Contains(t, out, "<svg")
+	assert.Contains(t, out, "2: for_start (L6-8) [i := 0; i &lt; 3; i++]")
+	assert.Contains(t, out, "</svg>")
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := cfg.ParseFormat("Mermaid")
+	require.NoError(t, err)
+	assert.Equal(t, cfg.FormatMermaid, f)
+
+	_, err = cfg.ParseFormat("png")
+	assert.Error(t, err)
+}
@@ -0,0 +1,174 @@
+package cfg
+
+import (
+	"math"
+	"slices"
+)
+
+// Branch describes a decision taken on a path
+type Branch struct {
+	// From is the branching vertex (eg. "if_start")
+	From int `json:"from"`
+	// To is the successor chosen on the path
+	To int `json:"to"`
+	// Kind of the edge taken, back edges of loops are followed to the exit of the loop
+	Kind EdgeKind `json:"kind,omitempty"`
+	// Condition is the source code of the condition (or case value) of the edge
+	Condition string `json:"condition,omitempty"`
+}
+
+// Path is an acyclic execution path from the start to the end vertex
+type Path struct {
+	Vertices []int     `json:"vertices"`
+	Branches []*Branch `json:"branches"`
+}
+
+// NPath returns the number of acyclic execution paths from the start to the end vertex.
+// Every loop is executed at most once (and skipped if the graph contains an edge bypassing
+// the body), the result saturates at math.MaxInt.
+func NPath(g Graph) (int, error) {
+	succ, err := acyclicSuccessors(g)
+	if err != nil {
+		return 0, err
+	}
+
+	// count the paths reaching the end vertex, memoized per vertex
+	counts := map[int]int{EndRef: 1}
+	var count func(int) int
+	count = func(v int) int {
+		if c, ok := counts[v]; ok {
+			return c
+		}
+		c := 0
+		for _, s := range succ[v] {
+			n := count(s)
+			if c > math.MaxInt-n {
+				c = math.MaxInt
+			} else {
+				c += n
+			}
+		}
+		counts[v] = c
+		return c
+	}
+
+	return count(StartRef), nil
+}
+
+// Paths enumerates the acyclic execution paths from the start to the end vertex in a stable order.
+// At most limit paths are returned, a limit <= 0 returns all paths.
+func Paths(g Graph, limit int) ([]*Path, error) {
+	succ, err := acyclicSuccessors(g)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []*Path{}
+	vertices := []int{}
+	branches := []*Branch{}
+
+	var walk func(int) bool
+	walk = func(v int) bool {
+		vertices = append(vertices, v)
+		defer func() { vertices = vertices[:len(vertices)-1] }()
+
+		if v == EndRef {
+			paths = append(paths, &Path{
+				Vertices: slices.Clone(vertices),
+				Branches: slices.Clone(branches),
+			})
+			return limit <= 0 || len(paths) < limit
+		}
+
+		for _, s := range succ[v] {
+			if len(succ[v]) > 1 {
+				branches = append(branches, newBranch(g, v, s))
+			}
+			more := walk(s)
+			if len(succ[v]) > 1 {
+				branches = branches[:len(branches)-1]
+			}
+			if !more {
+				return false
+			}
+		}
+		return true
+	}
+	walk(StartRef)
+
+	return paths, nil
+}
+
+// creates a branch based on the attributes of the edge
+func newBranch(g Graph, from, to int) *Branch {
+	branch := &Branch{From: from, To: to}
+	e, err := g.Edge(from, to)
+	if err != nil {
+		// there is no such edge if a back edge got replaced by an edge to the loop exit
+		branch.Kind = EdgeLoopBack
+		if block, err := g.Vertex(from); err == nil {
+			branch.Condition = block.Condition
+		}
+		return branch
+	}
+	branch.Kind = EdgeKind(e.Properties.Attributes["kind"])
+	branch.Condition = e.Properties.Attributes["condition"]
+	return branch
+}
+
+// returns the successors of all vertices with the back edges of loops replaced by
+// edges to the exits of the loop, so every loop is either skipped or executed once
+func acyclicSuccessors(g Graph) (map[int][]int, error) {
+	a, err := Analyze(g)
+	if err != nil {
+		return nil, err
+	}
+	adjacencyMap, err := g.AdjacencyMap()
+	if err != nil {
+		return nil, err
+	}
+	succ := sortedRefs(adjacencyMap)
+
+	loops := map[int]*Loop{}
+	for _, l := range a.Loops {
+		loops[l.Header] = l
+	}
+
+	// resolves the target of an edge, back edges are replaced by the targets of all edges
+	// leaving the loop body (which may leave an outer loop as well)
+	var resolve func(v, s int) []int
+	resolve = func(v, s int) []int {
+		l, ok := loops[s]
+		if !ok || !a.Dominates(s, v) {
+			return []int{s}
+		}
+		targets := []int{}
+		for _, u := range l.Body {
+			for _, e := range succ[u] {
+				if slices.Contains(l.Body, e) {
+					continue
+				}
+				for _, t := range resolve(u, e) {
+					if !slices.Contains(targets, t) {
+						targets = append(targets, t)
+					}
+				}
+			}
+		}
+		return targets
+	}
+
+	acyclic := map[int][]int{}
+	for v, targets := range succ {
+		acyclic[v] = []int{}
+		for _, s := range targets {
+			for _, t := range resolve(v, s) {
+				if !slices.Contains(acyclic[v], t) {
+					acyclic[v] = append(acyclic[v], t)
+				}
+			}
+		}
+		slices.Sort(acyclic[v])
+	}
+	return acyclic, nil
+}
@@ -0,0 +1,116 @@
+package cfg_test
+
+import (
+	"testing"
+
+	"github.com/jochil/gcs/pkg/cfg"
+	"github.com/jochil/gcs/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createGraph(t *testing.T, path string) cfg.Graph {
+	t.Helper()
+	candidates, err := parser.ParseFile(path)
+	require.NoError(t, err)
+	candidates.CalcScore()
+	return candidates[0].ControlFlowGraph
+}
+
+func TestNPath(t *testing.T) {
+	tests := map[string]struct {
+		path  string
+		npath int
+	}{
+		"go_no_control":                {path: "testdata/cyclo/golang/a.go", npath: 1},
+		"go_simple_if":                 {path: "testdata/cyclo/golang/b.go", npath: 2},
+		"go_if_else":                   {path: "testdata/cyclo/golang/c.go", npath: 4},
+		"go_switch_no_default":         {path: "testdata/cyclo/golang/d.go", npath: 2},
+		"go_switch_default":            {path: "testdata/cyclo/golang/e.go", npath: 3},
+		"go_simple_for":                {path: "testdata/cyclo/golang/f.go", npath: 2},
+		"go_basic_blocks":              {path: "testdata/cyclo/golang/g.go", npath: 2},
+		"go_nested_for":                {path: "testdata/cyclo/golang/h.go", npath: 3},
+		"go_empty_branches":            {path: "testdata/cyclo/golang/i.go", npath: 6},
+		"go_early_returns":             {path: "testdata/cyclo/golang/j.go", npath: 3},
+		"java_while":                   {path: "testdata/cyclo/java/While.java", npath: 2},
+		"java_do":                      {path: "testdata/cyclo/java/Do.java", npath: 1},
+		"java_returns":                 {path: "testdata/cyclo/java/Return.java", npath: 6},
+		"javascript_switch_default":    {path: "testdata/cyclo/javascript/switchDefault.js", npath: 3},
+		"javascript_switch_no_default": {path: "testdata/cyclo/javascript/switch.js", npath: 2},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			npath, err := cfg.NPath(createGraph(t, tc.path))
+			require.NoError(t, err)
+			assert.Equal(t, tc.npath, npath)
+
+			// the enumeration has to match the amount of paths
+			paths, err := cfg.Paths(createGraph(t, tc.path), 0)
+			require.NoError(t, err)
+			assert.Len(t, paths, tc.npath)
+		})
+	}
+}
+
+func TestPaths_Conditions(t *testing.T) {
+	paths, err := cfg.Paths(createGraph(t, "testdata/cyclo/golang/b.go"), 0)
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+
+	assert.Equal(t, []int{0, 2, 3, 5, 1}, paths[0].Vertices)
+	assert.Equal(t, []*cfg.Branch{{From: 2, To: 3, Kind: cfg.EdgeFalse, Condition: "a > 0"}}, paths[0].Branches)
+
+	// the path ends with the return of the consequence
+	assert.Equal(t, []int{0, 2, 4, 1}, paths[1].Vertices)
+	assert.Equal(t, []*cfg.Branch{{From: 2, To: 4, Kind: cfg.EdgeTrue, Condition: "a > 0"}}, paths[1].Branches)
+}
+
+func TestPaths_EarlyReturns(t *testing.T) {
+	paths, err := cfg.Paths(createGraph(t, "testdata/cyclo/golang/j.go"), 0)
+	require.NoError(t, err)
+	require.Len(t, paths, 3)
+
+	// no path continues after a return
+	assert.Equal(t, []int{0, 2, 3, 5, 6, 8, 1}, paths[0].Vertices)
+	assert.Equal(t, []int{0, 2, 3, 5, 7, 1}, paths[1].Vertices)
+	assert.Equal(t, []*cfg.Branch{
+		{From: 2, To: 3, Kind: cfg.EdgeFalse, Condition: "a < 0"},
+		{From: 5, To: 7, Kind: cfg.EdgeTrue, Condition: "a == 0"},
+	}, paths[1].Branches)
+	assert.Equal(t, []int{0, 2, 4, 1}, paths[2].Vertices)
+}
+
+func TestPaths_Loop(t *testing.T) {
+	paths, err := cfg.Paths(createGraph(t, "testdata/cyclo/java/While.java"), 0)
+	require.NoError(t, err)
+	require.Len(t, paths, 2)
+
+	// skipping the loop and executing the body once
+	assert.Equal(t, []int{0, 2, 3, 4, 1}, paths[0].Vertices)
+	assert.Equal(t, []int{0, 2, 3, 5, 4, 1}, paths[1].Vertices)
+	assert.Equal(t, "i < 5", paths[1].Branches[0].Condition)
+	assert.Equal(t, cfg.EdgeTrue, paths[1].Branches[0].Kind)
+}
+
+func TestPaths_Switch(t *testing.T) {
+	paths, err := cfg.Paths(createGraph(t, "testdata/cyclo/golang/e.go"), 0)
+	require.NoError(t, err)
+	require.Len(t, paths, 3)
+
+	kinds := []cfg.EdgeKind{}
+	values := []string{}
+	for _, p := range paths {
+		require.Len(t, p.Branches, 1)
+		kinds = append(kinds, p.Branches[0].Kind)
+		values = append(values, p.Branches[0].Condition)
+	}
+	assert.Equal(t, []cfg.EdgeKind{cfg.EdgeCase, cfg.EdgeCase, cfg.EdgeDefault}, kinds)
+	assert.Equal(t, []string{"1", "2", ""}, values)
+}
+
+func TestPaths_Limit(t *testing.T) {
+	paths, err := cfg.Paths(createGraph(t, "testdata/cyclo/golang/c.go"), 2)
+	require.NoError(t, err)
+	assert.Len(t, paths, 2)
+}
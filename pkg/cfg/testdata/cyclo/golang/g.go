@@ -0,0 +1,15 @@
+package _
+
+import "fmt"
+
+// straight-line code should be collapsed into blocks
+func CycloG(a int) int {
+	b := a * 2
+	fmt.Println(b)
+	b++
+	if b > 10 {
+		fmt.Println("big")
+		b = 10
+	}
+	return b
+}
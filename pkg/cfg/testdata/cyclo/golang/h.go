@@ -0,0 +1,12 @@
+package _
+
+// nested loops
+func CycloH(a [][]int) int {
+	sum := 0
+	for i := range a {
+		for j := range a[i] {
+			sum += a[i][j]
+		}
+	}
+	return sum
+}
@@ -0,0 +1,11 @@
+package _
+
+// should have a cyclomatic complexity of 4
+func CycloI(a int) {
+	if a > 0 {
+	}
+	switch a {
+	case 1:
+	case 2:
+	}
+}
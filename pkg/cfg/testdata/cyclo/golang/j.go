@@ -0,0 +1,12 @@
+package _
+
+// should have 3 execution paths, the early returns end the function
+func CycloJ(a int) int {
+	if a < 0 {
+		return -1
+	}
+	if a == 0 {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,22 @@
+package _
+
+// the statements after continue, break and return are unreachable
+func CycloK(a []int) int {
+	sum := 0
+	for _, v := range a {
+		if v < 0 {
+			continue
+			sum--
+		}
+		if v > 100 {
+			break
+			sum = 100
+		}
+		sum += v
+	}
+	if sum == 0 {
+		return 0
+		sum = 1
+	}
+	return sum
+}
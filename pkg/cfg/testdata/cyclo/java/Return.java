@@ -0,0 +1,18 @@
+package org.example;
+
+public class Foo {
+  int CycloJ(int a) {
+    for (int i = 0; i < a; i++) {
+      if (i > 5) {
+        return i;
+      }
+    }
+    try {
+      return a / 2;
+    } catch (ArithmeticException e) {
+      return 0;
+    } finally {
+      System.out.println(a);
+    }
+  }
+}
@@ -0,0 +1,15 @@
+package org.example;
+
+public class Foo {
+  void CycloTry(String s) {
+    try {
+      parse(s);
+    } catch (IllegalArgumentException e) {
+      log(e);
+    } catch (IOException e) {
+      log(e);
+    } finally {
+      close();
+    }
+  }
+}
@@ -0,0 +1,7 @@
+function cycloTry(s) {
+  try {
+    parse(s);
+  } catch (e) {
+    log(e);
+  }
+}
@@ -0,0 +1,401 @@
+// Package churn attributes the local git history of a file to line ranges (eg. functions).
+// Only the object database of the repository is read, there is no network access.
+package churn
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// Churn describes how often a range of lines changed over time
+type Churn struct {
+	Commits      int       `json:"commits"`
+	LinesChanged int       `json:"lines_changed"`
+	Authors      []string  `json:"authors"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// operation of a line based diff
+type operation struct {
+	kind  diffmatchpatch.Operation
+	lines int
+}
+
+// revision is a commit changing a file together with the diff against its first parent
+type revision struct {
+	commit *object.Commit
+	ops    []operation
+}
+
+// a commit of the first parent chain with the blob hashes of the changed files before and after the commit,
+// a zero hash if the file does not exist
+type change struct {
+	commit *object.Commit
+	files  map[string][2]plumbing.Hash
+}
+
+// repository is an opened git repository, the first parent chain is walked once for all files
+type repository struct {
+	root string
+	// the object storage of go-git is not safe for concurrent use
+	mu      sync.Mutex
+	repo    *git.Repository
+	once    sync.Once
+	changes []*change
+	err     error
+}
+
+// history of a single file, loaded once
+type history struct {
+	once      sync.Once
+	revisions []*revision
+	// changes of the working tree against HEAD, nil if there are none
+	local []operation
+	err   error
+}
+
+// Analyzer calculates the churn of line ranges, repositories and
+// file histories are cached so it can be used for many functions
+type Analyzer struct {
+	mu           sync.Mutex
+	repositories map[string]*repository
+	histories    map[string]*history
+}
+
+func NewAnalyzer() *Analyzer {
+	return &Analyzer{
+		repositories: map[string]*repository{},
+		histories:    map[string]*history{},
+	}
+}
+
+// Churn returns the churn of the lines start to end (1-based, inclusive) of a file. The line numbers refer
+// to the file in the working tree, local changes are mapped to HEAD but not counted. The history is followed
+// along the first parents.
+func (a *Analyzer) Churn(path string, start, end int) (*Churn, error) {
+	h, err := a.history(path)
+	if err != nil {
+		return nil, err
+	}
+	if h.local != nil {
+		_, start, end = mapRange(h.local, start, end)
+	}
+
+	c := &Churn{Authors: []string{}}
+	for _, rev := range h.revisions {
+		if start > end {
+			// the range got created by a later commit
+			break
+		}
+
+		changed, oldStart, oldEnd := mapRange(rev.ops, start, end)
+		if changed > 0 {
+			c.Commits++
+			c.LinesChanged += changed
+			author := rev.commit.Author.Email
+			if !slices.Contains(c.Authors, author) {
+				c.Authors = append(c.Authors, author)
+			}
+			if rev.commit.Author.When.After(c.LastModified) {
+				c.LastModified = rev.commit.Author.When
+			}
+		}
+		start, end = oldStart, oldEnd
+	}
+
+	return c, nil
+}
+
+// returns the history of a file, the lock is only held for the lookup, so different files are loaded in parallel
+func (a *Analyzer) history(path string) (*history, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	h, ok := a.histories[absPath]
+	if !ok {
+		h = &history{}
+		a.histories[absPath] = h
+	}
+	a.mu.Unlock()
+
+	h.once.Do(func() {
+		h.revisions, h.local, h.err = a.loadHistory(absPath)
+	})
+	return h, h.err
+}
+
+func (a *Analyzer) loadHistory(absPath string) ([]*revision, []operation, error) {
+	r, err := a.repository(filepath.Dir(absPath))
+	if err != nil {
+		return nil, nil, err
+	}
+	relPath, err := filepath.Rel(r.root, absPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.once.Do(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.changes, r.err = firstParentChanges(r.repo)
+	})
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+
+	revisions, head, err := r.fileHistory(filepath.ToSlash(relPath))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(revisions) == 0 {
+		return revisions, nil, nil
+	}
+
+	// the line numbers of the working tree are mapped to HEAD first
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	var local []operation
+	if string(content) != head {
+		local = operations(head, string(content))
+	}
+	return revisions, local, nil
+}
+
+// finds the repository containing the given directory
+func (a *Analyzer) repository(dir string) (*repository, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for root, r := range a.repositories {
+		if dir == root || strings.HasPrefix(dir, root+string(filepath.Separator)) {
+			return r, nil
+		}
+	}
+
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open git repository for %s: %w", dir, err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	r := &repository{repo: repo, root: wt.Filesystem.Root()}
+	a.repositories[r.root] = r
+	return r, nil
+}
+
+// walks the first parent chain starting at HEAD and collects the changed files of every commit (newest first),
+// only the trees are compared, the file contents are read per file later on
+func firstParentChanges(repo *git.Repository) ([]*change, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []*change{}
+	for commit != nil {
+		var parent *object.Commit
+		var parentTree *object.Tree
+		if commit.NumParents() > 0 {
+			if parent, err = commit.Parent(0); err != nil {
+				return nil, err
+			}
+			if parentTree, err = parent.Tree(); err != nil {
+				return nil, err
+			}
+		}
+
+		diff, err := object.DiffTree(parentTree, tree)
+		if err != nil {
+			return nil, err
+		}
+		c := &change{commit: commit, files: map[string][2]plumbing.Hash{}}
+		for _, d := range diff {
+			if d.From.Name != "" {
+				hashes := c.files[d.From.Name]
+				hashes[0] = d.From.TreeEntry.Hash
+				c.files[d.From.Name] = hashes
+			}
+			if d.To.Name != "" {
+				hashes := c.files[d.To.Name]
+				hashes[1] = d.To.TreeEntry.Hash
+				c.files[d.To.Name] = hashes
+			}
+		}
+		changes = append(changes, c)
+		commit, tree = parent, parentTree
+	}
+	return changes, nil
+}
+
+// collects every commit changing the file until it got created, the content of the file in HEAD is returned
+// as well
+func (r *repository) fileHistory(path string) ([]*revision, string, error) {
+	history := []*revision{}
+	head := ""
+	for _, c := range r.changes {
+		hashes, ok := c.files[path]
+		if !ok || hashes[0] == hashes[1] {
+			// not changed or only the mode
+			continue
+		}
+		if hashes[1] == plumbing.ZeroHash {
+			// file does not exist (anymore) in this commit
+			break
+		}
+
+		parentContent, content, err := r.contents(hashes)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(history) == 0 {
+			head = content
+		}
+		history = append(history, &revision{
+			commit: c.commit,
+			ops:    operations(parentContent, content),
+		})
+		if hashes[0] == plumbing.ZeroHash {
+			// created by this commit
+			break
+		}
+	}
+	return history, head, nil
+}
+
+// returns the contents of the given blobs, an empty string for a zero hash
+func (r *repository) contents(hashes [2]plumbing.Hash) (before string, after string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	contents := [2]string{}
+	for i, hash := range hashes {
+		if hash == plumbing.ZeroHash {
+			continue
+		}
+		if contents[i], err = blobContent(r.repo, hash); err != nil {
+			return "", "", err
+		}
+	}
+	return contents[0], contents[1], nil
+}
+
+// returns the content of a blob
+func blobContent(repo *git.Repository, hash plumbing.Hash) (string, error) {
+	blob, err := repo.BlobObject(hash)
+	if err != nil {
+		return "", err
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	return string(content), err
+}
+
+// calculates the line based diff operations turning src into dst
+func operations(src, dst string) []operation {
+	ops := []operation{}
+	for _, d := range diff.Do(src, dst) {
+		ops = append(ops, operation{kind: d.Type, lines: countLines(d.Text)})
+	}
+	return ops
+}
+
+func countLines(text string) int {
+	lines := strings.Count(text, "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		lines++
+	}
+	return lines
+}
+
+// counts the changed lines inside the range start-end of the new version and maps the
+// range to the old version. An empty range (start > end) is returned if all lines were added
+// without replacing existing lines.
+func mapRange(ops []operation, start, end int) (changed int, oldStart int, oldEnd int) {
+	oldLine, newLine := 1, 1
+	oldStart, oldEnd = -1, -1
+	// old lines removed inside of the range
+	deletedStart, deletedEnd := -1, -1
+
+	for i, op := range ops {
+		switch op.kind {
+		case diffmatchpatch.DiffEqual:
+			for i := 0; i < op.lines; i++ {
+				if newLine == start {
+					oldStart = oldLine
+				}
+				if newLine == end {
+					oldEnd = oldLine
+				}
+				oldLine++
+				newLine++
+			}
+
+		case diffmatchpatch.DiffInsert:
+			for i := 0; i < op.lines; i++ {
+				if newLine >= start && newLine <= end {
+					changed++
+				}
+				if newLine == start {
+					// the first line got added, the range starts with the next old line
+					oldStart = oldLine
+				}
+				if newLine == end {
+					// the last line got added, the range ends before
+					oldEnd = oldLine - 1
+				}
+				newLine++
+			}
+
+		case diffmatchpatch.DiffDelete:
+			// lines removed between the first and the last line of the range,
+			// or replaced by the first line of the range
+			replaced := newLine == start && i+1 < len(ops) && ops[i+1].kind == diffmatchpatch.DiffInsert
+			if (newLine > start && newLine <= end) || replaced {
+				changed += op.lines
+				if deletedStart == -1 {
+					deletedStart = oldLine
+				}
+				deletedEnd = oldLine + op.lines - 1
+			}
+			oldLine += op.lines
+		}
+	}
+
+	if oldStart == -1 || oldEnd == -1 || oldStart > oldEnd {
+		if deletedStart != -1 {
+			// the range got rewritten completely, follow the replaced lines
+			return changed, deletedStart, deletedEnd
+		}
+		return changed, 1, 0
+	}
+	return changed, oldStart, oldEnd
+}
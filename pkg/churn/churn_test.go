@@ -0,0 +1,176 @@
+package churn_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jochil/gcs/pkg/churn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	v1 = `package main
+
+func A() int {
+	return 1
+}
+`
+	// B gets added
+	v2 = `package main
+
+func A() int {
+	return 1
+}
+
+func B(a int) int {
+	return a
+}
+`
+	// A gets changed by another author, B moves down
+	v3 = `package main
+
+func A() int {
+	x := 1
+	return x
+}
+
+func B(a int) int {
+	return a
+}
+`
+	// only the package clause gets changed
+	v4 = `package foo
+
+func A() int {
+	x := 1
+	return x
+}
+
+func B(a int) int {
+	return a
+}
+`
+)
+
+func commit(t *testing.T, repo *git.Repository, dir, content, email string, when time.Time) {
+	t.Helper()
+	commitFile(t, repo, dir, "main.go", content, email, when)
+}
+
+func commitFile(t *testing.T, repo *git.Repository, dir, name, content, email string, when time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Add(name)
+	require.NoError(t, err)
+	_, err = wt.Commit("update", &git.CommitOptions{
+		Author: &object.Signature{Name: email, Email: email, When: when},
+	})
+	require.NoError(t, err)
+}
+
+func TestChurn(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	day := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	commit(t, repo, dir, v1, "alice@example.com", day)
+	commit(t, repo, dir, v2, "alice@example.com", day.AddDate(0, 0, 1))
+	commit(t, repo, dir, v3, "bob@example.com", day.AddDate(0, 0, 2))
+	commit(t, repo, dir, v4, "carol@example.com", day.AddDate(0, 0, 3))
+
+	testCases := []struct {
+		name         string
+		start        int
+		end          int
+		commits      int
+		linesChanged int
+		authors      []string
+		lastModified time.Time
+	}{
+		// created with 3 lines, 1 line removed + 2 lines added
+		{"A", 3, 6, 2, 6, []string{"bob@example.com", "alice@example.com"}, day.AddDate(0, 0, 2)},
+		{"B", 8, 10, 1, 3, []string{"alice@example.com"}, day.AddDate(0, 0, 1)},
+		{"package", 1, 1, 2, 3, []string{"carol@example.com", "alice@example.com"}, day.AddDate(0, 0, 3)},
+	}
+
+	a := churn.NewAnalyzer()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := a.Churn(filepath.Join(dir, "main.go"), tc.start, tc.end)
+			require.NoError(t, err)
+			assert.Equal(t, tc.commits, c.Commits)
+			assert.Equal(t, tc.linesChanged, c.LinesChanged)
+			assert.Equal(t, tc.authors, c.Authors)
+			assert.True(t, tc.lastModified.Equal(c.LastModified), "wrong last modified date: %s", c.LastModified)
+		})
+	}
+}
+
+func TestChurn_NoRepository(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(path, []byte(v1), 0o644))
+
+	_, err := churn.NewAnalyzer().Churn(path, 1, 3)
+	require.Error(t, err)
+}
+
+func TestChurn_LocalChanges(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	day := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	commit(t, repo, dir, v1, "alice@example.com", day)
+	commit(t, repo, dir, v2, "alice@example.com", day.AddDate(0, 0, 1))
+
+	// B moves down by two lines and C is not committed yet
+	local := strings.Replace(v2, "func A", "// A returns 1\n// uncommitted\nfunc A", 1) + "\nfunc C() {}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(local), 0o644))
+
+	a := churn.NewAnalyzer()
+	c, err := a.Churn(filepath.Join(dir, "main.go"), 9, 11)
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.Commits, "B")
+	assert.Equal(t, 3, c.LinesChanged, "B")
+
+	c, err = a.Churn(filepath.Join(dir, "main.go"), 13, 13)
+	require.NoError(t, err)
+	assert.Zero(t, c.Commits, "C")
+}
+
+func TestChurn_Files(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	day := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	commitFile(t, repo, dir, "a.go", v1, "alice@example.com", day)
+	commitFile(t, repo, dir, "b.go", v1, "bob@example.com", day.AddDate(0, 0, 1))
+	commitFile(t, repo, dir, "a.go", v3, "carol@example.com", day.AddDate(0, 0, 2))
+	commitFile(t, repo, dir, "c.go", v1, "carol@example.com", day.AddDate(0, 0, 3))
+
+	expected := map[string]int{"a.go": 2, "b.go": 1, "c.go": 1}
+	a := churn.NewAnalyzer()
+	wg := sync.WaitGroup{}
+	for name, commits := range expected {
+		wg.Add(1)
+		go func(name string, commits int) {
+			defer wg.Done()
+			c, err := a.Churn(filepath.Join(dir, name), 1, 20)
+			assert.NoError(t, err)
+			assert.Equal(t, commits, c.Commits, name)
+		}(name, commits)
+	}
+	wg.Wait()
+}
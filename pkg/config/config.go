@@ -0,0 +1,330 @@
+// Package config reads the .gcs.yaml configuration containing the scoring profiles (weights per metric and
+// language or a score expression) and additional sinks.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jochil/gcs/pkg/expr"
+	"github.com/jochil/gcs/pkg/normalize"
+	"github.com/jochil/gcs/pkg/sinks"
+	"github.com/jochil/gcs/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Filename is the name of the config file searched in the scanned directories and the working directory
+const Filename = ".gcs.yaml"
+
+// DefaultProfile is used if neither the config nor the command line selects a profile
+const DefaultProfile = "fuzz"
+
+// Weights are the weights of the normalized metrics indexed by their short name (eg. "cc", "cog")
+type Weights map[string]float64
+
+// Profile is a use case specific set of weights, it overrides the weights of the built-in profile with
+// the same name (or the weights of the fuzz profile for a new one)
+type Profile struct {
+	Weights Weights `yaml:"weights,omitempty"`
+	// Languages override the weights for a single language (eg. "java")
+	Languages map[string]Weights `yaml:"languages,omitempty"`
+	// Score is an expression over the normalized metrics replacing the weighted sum (eg. "2*cc + 4*cov - 5*unit")
+	Score string `yaml:"score,omitempty"`
+	// Normalization overrides the normalization strategy of the config
+	Normalization string `yaml:"normalization,omitempty"`
+	// Standalone profiles ignore the weights and languages applied to all profiles (eg. trained profiles)
+	Standalone bool `yaml:"standalone,omitempty"`
+}
+
+// Config is the content of a config file
+type Config struct {
+	// Profile is used if no profile is selected by the command line
+	Profile string `yaml:"profile,omitempty"`
+	// Weights and Languages are applied to all profiles
+	Weights   Weights             `yaml:"weights,omitempty"`
+	Languages map[string]Weights  `yaml:"languages,omitempty"`
+	Profiles  map[string]*Profile `yaml:"profiles,omitempty"`
+	// Normalization is the strategy used to normalize the metrics (max, minmax, rank, zscore, fixed)
+	Normalization string `yaml:"normalization,omitempty"`
+	// Thresholds override the thresholds of the fixed normalization (see normalize.Thresholds)
+	Thresholds Weights `yaml:"thresholds,omitempty"`
+	// Sinks extend the default catalog of sinks (see sinks.Load)
+	Sinks []*sinks.Category `yaml:"sinks,omitempty"`
+}
+
+// Scoring is the resolved scoring of a profile
+type Scoring struct {
+	Name      string
+	weights   Weights
+	languages map[string]Weights
+	// Score replaces the weighted sum if set
+	Score         *expr.Expression
+	Normalization normalize.Strategy
+	thresholds    Weights
+	// custom metrics without a default threshold
+	unbounded []string
+}
+
+// Load reads a config file
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("unable to read config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// SaveProfile adds or replaces a profile in a config file, the rest of the file (including the comments) is kept.
+// The file is created if it does not exist.
+func SaveProfile(path string, name string, profile *Profile) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return fmt.Errorf("unable to read config %s: %w", path, err)
+	}
+	if doc.Kind != yaml.DocumentNode {
+		doc = &yaml.Node{Kind: yaml.DocumentNode}
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("unable to update config %s: not a mapping", path)
+	}
+
+	value := &yaml.Node{}
+	if err := value.Encode(profile); err != nil {
+		return err
+	}
+	profiles := mappingValue(root, "profiles")
+	if profiles.Kind != yaml.MappingNode {
+		// eg. an empty profiles key
+		*profiles = yaml.Node{Kind: yaml.MappingNode}
+	}
+	*mappingValue(profiles, name) = *value
+
+	out := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	return os.WriteFile(path, out.Bytes(), 0o644)
+}
+
+// returns the value of a key in a mapping node, missing keys are added
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
+
+// Find returns the path of the first config file found in the given directories, an empty string if there is none
+func Find(dirs ...string) string {
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+			dir = filepath.Dir(dir)
+		}
+		path := filepath.Join(dir, Filename)
+		if _, err := os.Stat(path); err == nil {
+			slog.Info("found config", "path", path)
+			return path
+		}
+	}
+	return ""
+}
+
+// Catalog returns the default catalog of sinks extended by the sinks of the config
+func (c *Config) Catalog() sinks.Catalog {
+	if c == nil {
+		return sinks.Default
+	}
+	return sinks.Default.Merge(c.Sinks...)
+}
+
+// Scoring resolves the weights and score expression of a profile, an empty name selects the profile of the
+// config or the default profile
+func (c *Config) Scoring(name string) (*Scoring, error) {
+	if c == nil {
+		c = &Config{}
+	}
+	if name == "" {
+		name = c.Profile
+	}
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	base, builtin := Profiles[name]
+	custom, ok := c.Profiles[name]
+	if !builtin && !ok {
+		return nil, fmt.Errorf("unknown profile %s, available profiles: %s", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	if !builtin {
+		base = Profiles[DefaultProfile]
+	}
+
+	s := &Scoring{Name: name, weights: maps.Clone(base), languages: map[string]Weights{}}
+	s.thresholds, s.unbounded = defaultThresholds()
+	if custom == nil || !custom.Standalone {
+		if err := s.apply(c.Weights, c.Languages); err != nil {
+			return nil, err
+		}
+	}
+	if err := check(c.Thresholds); err != nil {
+		return nil, err
+	}
+	maps.Copy(s.thresholds, c.Thresholds)
+
+	if custom != nil {
+		if err := s.apply(custom.Weights, custom.Languages); err != nil {
+			return nil, err
+		}
+		if custom.Score != "" {
+			score, err := expr.Parse(custom.Score)
+			if err != nil {
+				return nil, err
+			}
+			if err := score.Check(MetricNames()); err != nil {
+				return nil, err
+			}
+			s.Score = score
+		}
+	}
+
+	// set last, the fixed normalization checks the thresholds of the weighted metrics
+	normalization := c.Normalization
+	if custom != nil && custom.Normalization != "" {
+		normalization = custom.Normalization
+	}
+	if err := s.SetNormalization(normalization); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// DefaultScoring returns the scoring of the default profile
+func DefaultScoring() *Scoring {
+	// the built-in profiles are always valid
+	scoring, _ := (*Config)(nil).Scoring("")
+	return scoring
+}
+
+// ProfileNames returns the sorted names of the built-in and configured profiles
+func (c *Config) ProfileNames() []string {
+	names := []string{}
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	if c != nil {
+		for name := range c.Profiles {
+			if _, ok := Profiles[name]; !ok {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Scoring) apply(weights Weights, languages map[string]Weights) error {
+	if err := check(weights); err != nil {
+		return err
+	}
+	maps.Copy(s.weights, weights)
+
+	for lang, w := range languages {
+		if err := check(w); err != nil {
+			return err
+		}
+		lang = strings.ToLower(lang)
+		if s.languages[lang] == nil {
+			s.languages[lang] = Weights{}
+		}
+		maps.Copy(s.languages[lang], w)
+	}
+	return nil
+}
+
+// SetNormalization sets the normalization strategy by its name, an empty name selects the default strategy.
+// The fixed strategy requires a threshold for every weighted custom metric.
+func (s *Scoring) SetNormalization(name string) error {
+	strategy, err := normalize.ParseStrategy(name)
+	if err != nil {
+		return err
+	}
+	if strategy == normalize.Fixed {
+		errs := []error{}
+		for _, metric := range s.unbounded {
+			if s.thresholds[metric] <= 0 && s.weighted(metric) {
+				errs = append(errs, fmt.Errorf("metric %s has no threshold for the fixed normalization, add it to thresholds", metric))
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+	}
+	s.Normalization = strategy
+	return nil
+}
+
+// returns true if the metric is used by the score expression or has a weight for any language
+func (s *Scoring) weighted(metric string) bool {
+	if s.Score != nil {
+		return slices.Contains(s.Score.Variables(), metric)
+	}
+	if s.weights[metric] != 0 {
+		return true
+	}
+	for _, weights := range s.languages {
+		if weights[metric] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Threshold returns the threshold of a metric used by the fixed normalization
+func (s *Scoring) Threshold(metric string) float64 {
+	return s.thresholds[metric]
+}
+
+// Weights returns the weights for a language
+func (s *Scoring) Weights(language types.Language) Weights {
+	weights := maps.Clone(s.weights)
+	maps.Copy(weights, s.languages[strings.ToLower(language.String())])
+	return weights
+}
+
+// returns an error for unknown metric names
+func check(weights Weights) error {
+	names := MetricNames()
+	errs := []error{}
+	for name := range weights {
+		if !slices.Contains(names, name) {
+			errs = append(errs, fmt.Errorf("unknown metric %s, available metrics: %s", name, strings.Join(names, ", ")))
+		}
+	}
+	return errors.Join(errs...)
+}
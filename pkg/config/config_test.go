@@ -0,0 +1,206 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jochil/gcs/pkg/config"
+	"github.com/jochil/gcs/pkg/normalize"
+	"github.com/jochil/gcs/pkg/sinks"
+	"github.com/jochil/gcs/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoring(t *testing.T) {
+	cfg, err := config.Load("testdata/config.yaml")
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		profile  string
+		language types.Language
+		expected map[string]float64
+		score    string
+	}{
+		"config_profile": {
+			profile:  "",
+			language: types.Go,
+			expected: map[string]float64{"cc": 10, "loc": 0, "name": 5, "sink": 4},
+			score:    "2*cc + 4*cov - 5*unit",
+		},
+		"fuzz": {
+			profile:  "fuzz",
+			language: types.Go,
+			expected: map[string]float64{"cc": 2, "loc": 0, "name": 5, "sink": 6},
+		},
+		"fuzz_java": {
+			profile:  "fuzz",
+			language: types.Java,
+			expected: map[string]float64{"cc": 2, "loc": 0, "name": 1, "sink": 6},
+		},
+		"unit": {
+			profile:  "unit",
+			language: types.Go,
+			expected: map[string]float64{"cc": 3, "loc": 0, "name": 0, "unit": -5},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			scoring, err := cfg.Scoring(tc.profile)
+			require.NoError(t, err)
+			weights := scoring.Weights(tc.language)
+			for metric, weight := range tc.expected {
+				assert.Equal(t, weight, weights[metric], metric)
+			}
+			if tc.score == "" {
+				assert.Nil(t, scoring.Score)
+			} else {
+				require.NotNil(t, scoring.Score)
+				assert.Equal(t, tc.score, scoring.Score.String())
+			}
+		})
+	}
+
+	_, err = cfg.Scoring("missing")
+	require.Error(t, err)
+	assert.Equal(t, []string{"fuzz", "team", "unit"}, cfg.ProfileNames())
+}
+
+func TestScoring_Default(t *testing.T) {
+	scoring := config.DefaultScoring()
+	assert.Equal(t, config.DefaultProfile, scoring.Name)
+	assert.Equal(t, config.Weights(config.Profiles[config.DefaultProfile]), scoring.Weights(types.Go))
+	assert.Nil(t, scoring.Score)
+}
+
+func TestScoring_Invalid(t *testing.T) {
+	cfg, err := config.Load("testdata/invalid.yaml")
+	require.NoError(t, err)
+
+	_, err = cfg.Scoring("fuzz")
+	require.ErrorContains(t, err, "unknown metric unknown")
+	_, err = cfg.Scoring("expr")
+	require.ErrorContains(t, err, "unknown variable missing")
+}
+
+func TestCatalog(t *testing.T) {
+	cfg, err := config.Load("testdata/config.yaml")
+	require.NoError(t, err)
+	assert.Len(t, cfg.Catalog(), len(sinks.Default)+1)
+	assert.Len(t, (*config.Config)(nil).Catalog(), len(sinks.Default))
+}
+
+func TestFind(t *testing.T) {
+	assert.Equal(t, filepath.Join("testdata/project", config.Filename), config.Find("testdata", "testdata/project"))
+	assert.Equal(t, filepath.Join("testdata/project", config.Filename), config.Find("testdata/project/.gcs.yaml"))
+	assert.Empty(t, config.Find("testdata"))
+}
+
+func TestScoring_CustomThresholds(t *testing.T) {
+	require.NoError(t, config.AddMetric("todo", 0))
+	t.Cleanup(func() { config.RemoveMetric("todo") })
+	require.Error(t, config.AddMetric("todo", 5), "duplicate metric")
+
+	// not weighted, so no threshold is needed
+	cfg := &config.Config{Normalization: "fixed"}
+	_, err := cfg.Scoring("")
+	require.NoError(t, err)
+
+	testCases := map[string]*config.Config{
+		"weights":   {Weights: config.Weights{"todo": 1}},
+		"languages": {Languages: map[string]config.Weights{"go": {"todo": 1}}},
+		"score":     {Profiles: map[string]*config.Profile{"fuzz": {Score: "cc + todo"}}},
+	}
+	for name, cfg := range testCases {
+		t.Run(name, func(t *testing.T) {
+			scoring, err := cfg.Scoring("")
+			require.NoError(t, err)
+			require.ErrorContains(t, scoring.SetNormalization("fixed"), "todo has no threshold")
+
+			cfg.Normalization = "fixed"
+			_, err = cfg.Scoring("")
+			require.Error(t, err)
+
+			cfg.Thresholds = config.Weights{"todo": 10}
+			scoring, err = cfg.Scoring("")
+			require.NoError(t, err)
+			assert.Equal(t, 10.0, scoring.Threshold("todo"))
+		})
+	}
+}
+
+func TestScoring_Normalization(t *testing.T) {
+	cfg := &config.Config{
+		Normalization: "fixed",
+		Thresholds:    config.Weights{"cc": 50},
+		Profiles: map[string]*config.Profile{
+			"ranked": {Normalization: "rank"},
+		},
+	}
+
+	scoring, err := cfg.Scoring("fuzz")
+	require.NoError(t, err)
+	assert.Equal(t, normalize.Fixed, scoring.Normalization)
+	assert.Equal(t, 50.0, scoring.Threshold("cc"))
+	assert.Equal(t, normalize.Thresholds["loc"], scoring.Threshold("loc"))
+
+	scoring, err = cfg.Scoring("ranked")
+	require.NoError(t, err)
+	assert.Equal(t, normalize.Rank, scoring.Normalization)
+	require.Error(t, scoring.SetNormalization("median"))
+
+	assert.Equal(t, normalize.Max, config.DefaultScoring().Normalization)
+
+	cfg.Normalization = "median"
+	_, err = cfg.Scoring("fuzz")
+	require.Error(t, err)
+}
+
+func TestSaveProfile(t *testing.T) {
+	original, err := os.ReadFile("testdata/config.yaml")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), config.Filename)
+	require.NoError(t, os.WriteFile(path, append([]byte("# weights of the team\n"), original...), 0o644))
+
+	profile := &config.Profile{Weights: config.Weights{"cc": 1.5}, Normalization: "fixed", Standalone: true}
+	require.NoError(t, config.SaveProfile(path, "trained", profile))
+	// replaces the existing profile
+	profile.Weights["cc"] = 2.5
+	require.NoError(t, config.SaveProfile(path, "trained", profile))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# weights of the team\n")
+	assert.Equal(t, 1, strings.Count(string(data), "trained:"))
+
+	expected, err := config.Load("testdata/config.yaml")
+	require.NoError(t, err)
+	expected.Profiles["trained"] = profile
+	saved, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, expected, saved)
+
+	// new config file
+	path = filepath.Join(t.TempDir(), config.Filename)
+	require.NoError(t, config.SaveProfile(path, "trained", profile))
+	saved, err = config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, &config.Config{Profiles: map[string]*config.Profile{"trained": profile}}, saved)
+}
+
+func TestScoring_Standalone(t *testing.T) {
+	cfg, err := config.Load("testdata/config.yaml")
+	require.NoError(t, err)
+	cfg.Profiles["trained"] = &config.Profile{Weights: config.Weights{"cc": 1}, Standalone: true}
+
+	scoring, err := cfg.Scoring("trained")
+	require.NoError(t, err)
+	// the weights and languages of the config are not applied
+	weights := scoring.Weights(types.Java)
+	assert.Equal(t, 1.0, weights["cc"])
+	assert.Equal(t, config.Profiles["fuzz"]["loc"], weights["loc"])
+	assert.Equal(t, config.Profiles["fuzz"]["name"], weights["name"])
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/jochil/gcs/pkg/normalize"
+)
+
+// Profiles are the built-in profiles
+var Profiles = map[string]Weights{
+	// cyclomatic complexity over-ranks flat switch statements, so the
+	// cognitive complexity gets the higher weight
+	"fuzz": {
+		"cc":   2,
+		"cog":  3,
+		"loc":  1,
+		"name": 5,
+		"prim": 0, // replaced by the data flow metrics
+		// functions taking raw bytes/strings and returning an error are the classic fuzz target
+		"raw":    3,
+		"err":    1,
+		"params": 0, // not weighting it by now, as this is more of a filter
+		// the shape of the result is more of a filter as well (see ReturnsValue and Throws)
+		"ret":    0,
+		"throws": 0,
+		// frequently changed code is more likely to contain bugs
+		"churn": 3,
+		// untested complex code
+		"cov": 4,
+		// functions already having tests or fuzz targets are less interesting
+		"unit": -1,
+		"fuzz": -5,
+		// functions reached by many public APIs are valuable targets
+		"fanin": 1,
+		"entry": 3,
+		// calls of dangerous functions (weighted by their category)
+		"sink": 4,
+		// the control flow and sinks have to be reachable by the fuzzer input
+		"pbranch": 4,
+		"tsink":   3,
+	},
+	// complex, frequently changed and untested code, the shape of the input does not matter
+	"unit": {
+		"cc":      3,
+		"cog":     4,
+		"loc":     1,
+		"name":    0,
+		"prim":    0,
+		"raw":     0,
+		"err":     1,
+		"params":  0,
+		"ret":     0,
+		"throws":  0,
+		"churn":   3,
+		"cov":     6,
+		"unit":    -5,
+		"fuzz":    0,
+		"fanin":   2,
+		"entry":   2,
+		"sink":    1,
+		"pbranch": 1,
+		"tsink":   0,
+	},
+}
+
+var (
+	// names of the built-in and custom metrics, sorted
+	metrics = func() []string {
+		names := []string{}
+		for name := range Profiles[DefaultProfile] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names
+	}()
+	// default thresholds of the custom metrics for the fixed normalization, 0 if there is none
+	customThresholds = map[string]float64{}
+	metricsMu        sync.RWMutex
+)
+
+// MetricNames returns the sorted names of the normalized metrics usable in weights and score expressions,
+// including the registered custom metrics
+func MetricNames() []string {
+	metricsMu.RLock()
+	defer metricsMu.RUnlock()
+	return slices.Clone(metrics)
+}
+
+// AddMetric adds the name of a custom metric, it gets a weight of 0 in all built-in profiles. The threshold is
+// the default for the fixed normalization, without a threshold (0) the config has to set one if the metric is
+// weighted.
+func AddMetric(name string, threshold float64) error {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	if slices.Contains(metrics, name) {
+		return fmt.Errorf("metric %s already exists", name)
+	}
+	metrics = append(metrics, name)
+	sort.Strings(metrics)
+	customThresholds[name] = threshold
+	return nil
+}
+
+// RemoveMetric removes the name of a custom metric
+func RemoveMetric(name string) {
+	if _, ok := Profiles[DefaultProfile][name]; ok {
+		return
+	}
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	metrics = slices.DeleteFunc(metrics, func(m string) bool { return m == name })
+	delete(customThresholds, name)
+}
+
+// returns the default thresholds of the built-in and custom metrics, the custom metrics without a threshold are
+// returned separately
+func defaultThresholds() (thresholds Weights, missing []string) {
+	metricsMu.RLock()
+	defer metricsMu.RUnlock()
+	thresholds = maps.Clone(normalize.Thresholds)
+	for name, threshold := range customThresholds {
+		if threshold > 0 {
+			thresholds[name] = threshold
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return thresholds, missing
+}
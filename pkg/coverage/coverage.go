@@ -0,0 +1,244 @@
+// Package coverage imports line and branch coverage from existing coverage reports.
+// Supported formats are Go coverprofiles, LCOV, JaCoCo XML and Cobertura XML.
+package coverage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type Format string
+
+const (
+	FormatGo        Format = "go"
+	FormatLCOV      Format = "lcov"
+	FormatJaCoCo    Format = "jacoco"
+	FormatCobertura Format = "cobertura"
+)
+
+// Branches holds the amount of (covered) branches of a line
+type Branches struct {
+	Total   int
+	Covered int
+}
+
+// File holds the coverage of a single source file
+type File struct {
+	// Path as stored in the report, may be relative or contain a module/package prefix
+	Path string
+	// Lines maps the line number to the amount of hits
+	Lines map[int]int
+	// Branches maps the line number to the branches starting in this line
+	Branches map[int]*Branches
+}
+
+func newFile(path string) *File {
+	return &File{
+		Path:     filepath.ToSlash(path),
+		Lines:    map[int]int{},
+		Branches: map[int]*Branches{},
+	}
+}
+
+// addLine adds hits to a line, lines reported multiple times are covered if one of them got hit
+func (f *File) addLine(line, hits int) {
+	if old, ok := f.Lines[line]; !ok || hits > old {
+		f.Lines[line] = hits
+	}
+}
+
+func (f *File) addBranches(line, total, covered int) {
+	b, ok := f.Branches[line]
+	if !ok {
+		b = &Branches{}
+		f.Branches[line] = b
+	}
+	b.Total += total
+	b.Covered += covered
+}
+
+// Coverage of a range of lines (eg. a function)
+type Coverage struct {
+	Lines           int
+	CoveredLines    int
+	Branches        int
+	CoveredBranches int
+}
+
+// LineCoverage returns the ratio (0-1) of covered lines
+func (c *Coverage) LineCoverage() float64 {
+	if c.Lines == 0 {
+		return 0
+	}
+	return float64(c.CoveredLines) / float64(c.Lines)
+}
+
+// BranchCoverage returns the ratio (0-1) of covered branches,
+// ranges without any branches have the same coverage as their lines
+func (c *Coverage) BranchCoverage() float64 {
+	if c.Branches == 0 {
+		return c.LineCoverage()
+	}
+	return float64(c.CoveredBranches) / float64(c.Branches)
+}
+
+// Profile holds the coverage of all files found in one or more reports
+type Profile struct {
+	Files map[string]*File
+	// cached go module paths per directory, the lookup is safe for concurrent use
+	mu      sync.Mutex
+	modules map[string]string
+}
+
+func NewProfile() *Profile {
+	return &Profile{Files: map[string]*File{}, modules: map[string]string{}}
+}
+
+// Load reads the coverage reports at the given paths, the format is detected based on the content
+func Load(paths ...string) (*Profile, error) {
+	p := NewProfile()
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		format, err := DetectFormat(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if err := p.Add(bytes.NewReader(data), format); err != nil {
+			return nil, fmt.Errorf("unable to read %s coverage report %s: %w", format, path, err)
+		}
+	}
+	return p, nil
+}
+
+// DetectFormat guesses the format of a coverage report
+func DetectFormat(data []byte) (Format, error) {
+	content := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(content, "mode:"):
+		return FormatGo, nil
+	case strings.HasPrefix(content, "TN:"), strings.HasPrefix(content, "SF:"):
+		return FormatLCOV, nil
+	case strings.Contains(content, "<report"):
+		return FormatJaCoCo, nil
+	case strings.Contains(content, "<coverage"):
+		return FormatCobertura, nil
+	}
+	return "", fmt.Errorf("unknown coverage format")
+}
+
+// File returns the coverage of a source file. As reports often contain relative paths (or
+// paths prefixed by a module/package name) the file with the longest matching path suffix is used.
+// Go coverprofiles use import paths, they are resolved by the go.mod file next to the source file.
+func (p *Profile) File(path string) (*File, bool) {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if importPath := p.importPath(path); importPath != "" {
+		if f, ok := p.Files[importPath]; ok {
+			return f, true
+		}
+	}
+	path = filepath.ToSlash(path)
+	if f, ok := p.Files[path]; ok {
+		return f, true
+	}
+
+	var match *File
+	for _, f := range p.Files {
+		if !matchSuffix(path, f.Path) {
+			continue
+		}
+		if match == nil || len(f.Path) > len(match.Path) || (len(f.Path) == len(match.Path) && f.Path < match.Path) {
+			match = f
+		}
+	}
+	return match, match != nil
+}
+
+// Coverage returns the coverage of the lines start to end (1-based, inclusive) of a source file
+func (p *Profile) Coverage(path string, start, end int) (*Coverage, bool) {
+	f, ok := p.File(path)
+	if !ok {
+		return nil, false
+	}
+
+	c := &Coverage{}
+	for line := start; line <= end; line++ {
+		if hits, ok := f.Lines[line]; ok {
+			c.Lines++
+			if hits > 0 {
+				c.CoveredLines++
+			}
+		}
+		if b, ok := f.Branches[line]; ok {
+			c.Branches += b.Total
+			c.CoveredBranches += b.Covered
+		}
+	}
+	return c, true
+}
+
+func (p *Profile) file(path string) *File {
+	path = filepath.ToSlash(filepath.Clean(path))
+	f, ok := p.Files[path]
+	if !ok {
+		f = newFile(path)
+		p.Files[path] = f
+	}
+	return f
+}
+
+// returns the go import path of a file based on the module declared in the nearest go.mod
+func (p *Profile) importPath(path string) string {
+	if filepath.Ext(path) != ".go" {
+		return ""
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	rel := filepath.Base(path)
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		module, ok := p.modules[dir]
+		if !ok {
+			module = modulePath(filepath.Join(dir, "go.mod"))
+			p.modules[dir] = module
+		}
+		if module != "" {
+			return module + "/" + filepath.ToSlash(rel)
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return ""
+		}
+		rel = filepath.Join(filepath.Base(dir), rel)
+	}
+}
+
+// reads the module path from a go.mod file
+func modulePath(gomod string) string {
+	data, err := os.ReadFile(gomod)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`)
+		}
+	}
+	return ""
+}
+
+// checks if path ends with suffix at a path separator
+func matchSuffix(path, suffix string) bool {
+	suffix = strings.TrimPrefix(suffix, "./")
+	if suffix == "" || !strings.HasSuffix(path, suffix) {
+		return false
+	}
+	return len(path) == len(suffix) || path[len(path)-len(suffix)-1] == '/'
+}
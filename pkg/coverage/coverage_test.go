@@ -0,0 +1,71 @@
+package coverage_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jochil/gcs/pkg/coverage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	testCases := map[string]struct {
+		report   string
+		format   coverage.Format
+		source   string
+		start    int
+		end      int
+		expected *coverage.Coverage
+	}{
+		"go":        {"testdata/go.out", coverage.FormatGo, "testdata/project/pkg/a/a.go", 3, 8, &coverage.Coverage{Lines: 5, CoveredLines: 3}},
+		"lcov":      {"testdata/lcov.info", coverage.FormatLCOV, "/home/user/project/src/a.js", 1, 5, &coverage.Coverage{Lines: 4, CoveredLines: 3, Branches: 2, CoveredBranches: 1}},
+		"jacoco":    {"testdata/jacoco.xml", coverage.FormatJaCoCo, "/home/user/project/src/main/java/com/example/A.java", 1, 10, &coverage.Coverage{Lines: 3, CoveredLines: 2, Branches: 2, CoveredBranches: 1}},
+		"cobertura": {"testdata/cobertura.xml", coverage.FormatCobertura, "/build/project/src/b.c", 1, 3, &coverage.Coverage{Lines: 2, CoveredLines: 1, Branches: 4, CoveredBranches: 1}},
+		"range":     {"testdata/lcov.info", coverage.FormatLCOV, "/home/user/project/src/a.js", 3, 4, &coverage.Coverage{Lines: 1}},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(tc.report)
+			require.NoError(t, err)
+			format, err := coverage.DetectFormat(data)
+			require.NoError(t, err)
+			assert.Equal(t, tc.format, format)
+
+			p, err := coverage.Load(tc.report)
+			require.NoError(t, err)
+			c, ok := p.Coverage(tc.source, tc.start, tc.end)
+			require.True(t, ok, "no coverage found for %s", tc.source)
+			assert.Equal(t, tc.expected, c)
+		})
+	}
+}
+
+func TestProfile_File(t *testing.T) {
+	p, err := coverage.Load("testdata/go.out", "testdata/lcov.info")
+	require.NoError(t, err)
+
+	_, ok := p.File("testdata/project/pkg/b/a.go")
+	assert.False(t, ok, "only the base name matches")
+	_, ok = p.File("/home/user/project/xsrc/a.js")
+	assert.False(t, ok, "suffix has to start at a path separator")
+	f, ok := p.File("/home/user/project/src/a.js")
+	require.True(t, ok)
+	assert.Equal(t, "src/a.js", f.Path)
+}
+
+func TestCoverage_Ratios(t *testing.T) {
+	c := &coverage.Coverage{Lines: 4, CoveredLines: 3}
+	assert.Equal(t, 0.75, c.LineCoverage())
+	assert.Equal(t, 0.75, c.BranchCoverage(), "functions without branches use the line coverage")
+
+	c.Branches, c.CoveredBranches = 4, 1
+	assert.Equal(t, 0.25, c.BranchCoverage())
+	assert.Zero(t, (&coverage.Coverage{}).LineCoverage())
+}
+
+func TestLoad_UnknownFormat(t *testing.T) {
+	_, err := coverage.Load("coverage.go")
+	require.Error(t, err)
+}
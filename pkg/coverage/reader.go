@@ -0,0 +1,233 @@
+package coverage
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Add reads a coverage report in the given format and merges it into the profile
+func (p *Profile) Add(r io.Reader, format Format) error {
+	switch format {
+	case FormatGo:
+		return p.addGo(r)
+	case FormatLCOV:
+		return p.addLCOV(r)
+	case FormatJaCoCo:
+		return p.addJaCoCo(r)
+	case FormatCobertura:
+		return p.addCobertura(r)
+	}
+	return fmt.Errorf("unsupported coverage format: %s", format)
+}
+
+// reads a go coverprofile, every line describes a block:
+// file.go:startLine.startCol,endLine.endCol numStatements count
+func (p *Profile) addGo(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+
+		sep := strings.LastIndex(line, ":")
+		if sep == -1 {
+			return fmt.Errorf("invalid line: %s", line)
+		}
+		fields := strings.Fields(line[sep+1:])
+		if len(fields) != 3 {
+			return fmt.Errorf("invalid line: %s", line)
+		}
+
+		start, end, ok := strings.Cut(fields[0], ",")
+		if !ok {
+			return fmt.Errorf("invalid block: %s", fields[0])
+		}
+		startLine, err := goPosition(start)
+		if err != nil {
+			return err
+		}
+		endLine, err := goPosition(end)
+		if err != nil {
+			return err
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return err
+		}
+
+		f := p.file(line[:sep])
+		for l := startLine; l <= endLine; l++ {
+			f.addLine(l, count)
+		}
+	}
+	return scanner.Err()
+}
+
+// returns the line of a position (line.column)
+func goPosition(pos string) (int, error) {
+	line, _, _ := strings.Cut(pos, ".")
+	return strconv.Atoi(line)
+}
+
+// reads a lcov tracefile (https://github.com/linux-test-project/lcov/blob/master/man/geninfo.1)
+func (p *Profile) addLCOV(r io.Reader) error {
+	var f *File
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		values := strings.Split(value, ",")
+
+		switch key {
+		case "SF":
+			f = p.file(value)
+
+		case "DA":
+			// DA:<line>,<hits>[,<checksum>]
+			if f == nil || len(values) < 2 {
+				return fmt.Errorf("invalid line record: %s", value)
+			}
+			line, err := strconv.Atoi(values[0])
+			if err != nil {
+				return err
+			}
+			hits, err := strconv.Atoi(values[1])
+			if err != nil {
+				return err
+			}
+			f.addLine(line, hits)
+
+		case "BRDA":
+			// BRDA:<line>,<block>,<branch>,<taken> where taken is "-" if the block was never executed
+			if f == nil || len(values) < 4 {
+				return fmt.Errorf("invalid branch record: %s", value)
+			}
+			line, err := strconv.Atoi(values[0])
+			if err != nil {
+				return err
+			}
+			covered := 0
+			if taken, err := strconv.Atoi(values[3]); err == nil && taken > 0 {
+				covered = 1
+			}
+			f.addBranches(line, 1, covered)
+
+		case "end_of_record":
+			f = nil
+		}
+	}
+	return scanner.Err()
+}
+
+type jacocoReport struct {
+	Packages []struct {
+		Name        string `xml:"name,attr"`
+		SourceFiles []struct {
+			Name  string `xml:"name,attr"`
+			Lines []struct {
+				Nr int `xml:"nr,attr"`
+				// missed/covered instructions
+				MI int `xml:"mi,attr"`
+				CI int `xml:"ci,attr"`
+				// missed/covered branches
+				MB int `xml:"mb,attr"`
+				CB int `xml:"cb,attr"`
+			} `xml:"line"`
+		} `xml:"sourcefile"`
+	} `xml:"package"`
+}
+
+// reads a JaCoCo xml report, source files are identified by their package path
+func (p *Profile) addJaCoCo(r io.Reader) error {
+	report := &jacocoReport{}
+	if err := newXMLDecoder(r).Decode(report); err != nil {
+		return err
+	}
+
+	for _, pkg := range report.Packages {
+		for _, sf := range pkg.SourceFiles {
+			f := p.file(path.Join(pkg.Name, sf.Name))
+			for _, l := range sf.Lines {
+				f.addLine(l.Nr, l.CI)
+				if l.MB+l.CB > 0 {
+					f.addBranches(l.Nr, l.MB+l.CB, l.CB)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type coberturaReport struct {
+	Packages []struct {
+		Classes []struct {
+			Filename string `xml:"filename,attr"`
+			Lines    []struct {
+				Number int    `xml:"number,attr"`
+				Hits   int    `xml:"hits,attr"`
+				Branch bool   `xml:"branch,attr"`
+				Cond   string `xml:"condition-coverage,attr"`
+			} `xml:"lines>line"`
+		} `xml:"classes>class"`
+	} `xml:"packages>package"`
+}
+
+// reads a Cobertura xml report, the filenames are relative to one of the source directories
+func (p *Profile) addCobertura(r io.Reader) error {
+	report := &coberturaReport{}
+	if err := newXMLDecoder(r).Decode(report); err != nil {
+		return err
+	}
+
+	for _, pkg := range report.Packages {
+		for _, class := range pkg.Classes {
+			f := p.file(class.Filename)
+			for _, l := range class.Lines {
+				f.addLine(l.Number, l.Hits)
+				if !l.Branch {
+					continue
+				}
+				covered, total, err := conditionCoverage(l.Cond)
+				if err != nil {
+					return err
+				}
+				f.addBranches(l.Number, total, covered)
+			}
+		}
+	}
+	return nil
+}
+
+// parses the condition coverage of cobertura, eg. "50% (1/2)"
+func conditionCoverage(cond string) (covered, total int, err error) {
+	start := strings.Index(cond, "(")
+	end := strings.Index(cond, ")")
+	if start == -1 || end < start {
+		return 0, 0, fmt.Errorf("invalid condition coverage: %s", cond)
+	}
+	c, t, ok := strings.Cut(cond[start+1:end], "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid condition coverage: %s", cond)
+	}
+	if covered, err = strconv.Atoi(c); err != nil {
+		return 0, 0, err
+	}
+	if total, err = strconv.Atoi(t); err != nil {
+		return 0, 0, err
+	}
+	return covered, total, nil
+}
+
+func newXMLDecoder(r io.Reader) *xml.Decoder {
+	d := xml.NewDecoder(r)
+	// reports are not always utf-8 encoded, the relevant attributes are ascii anyway
+	d.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	return d
+}
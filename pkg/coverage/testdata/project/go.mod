@@ -0,0 +1,3 @@
+module github.com/example/project
+
+go 1.21
@@ -0,0 +1,8 @@
+package a
+
+func A(x int) int {
+	if x > 10 {
+		return x
+	}
+	return 0
+}
@@ -0,0 +1,294 @@
+// Package dataflow tracks how the parameters of a function flow through its body. The analysis is
+// intra-procedural and flow-insensitive: every variable assigned from an expression using a tainted
+// variable gets tainted as well, starting with the parameters.
+package dataflow
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/jochil/gcs/pkg/helper"
+	"github.com/jochil/gcs/pkg/sinks"
+	"github.com/jochil/gcs/pkg/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// node types of branching statements, the condition is resolved by condition()
+var branchTypes = []string{
+	"if_statement",
+	"switch_statement",
+	"switch_expression",
+	"expression_switch_statement",
+	"ternary_expression",
+	"conditional_expression",
+}
+
+// node types of loops
+var loopTypes = []string{
+	"for_statement",
+	"while_statement",
+	"do_statement",
+	"enhanced_for_statement",
+	"for_in_statement",
+}
+
+// node types of array/slice/map accesses
+var indexTypes = []string{
+	"index_expression",
+	"slice_expression",
+	"array_access",
+	"subscript_expression",
+}
+
+// Result describes which parts of a function depend on its parameters
+type Result struct {
+	// Tainted are the sorted names of all variables depending on a parameter (including the parameters)
+	Tainted []string
+	// Branches is the number of branching statements and loops
+	Branches int
+	// ParamBranches is the number of branches with a condition depending on a parameter
+	ParamBranches int
+	// TaintedLoops is the number of loops with a bound depending on a parameter
+	TaintedLoops int
+	// TaintedIndexes is the number of index expressions using an index depending on a parameter
+	TaintedIndexes int
+	// SinkCalls is the number of calls of sinks (see sinks.Catalog)
+	SinkCalls int
+	// TaintedSinks is the number of sink calls with arguments depending on a parameter
+	TaintedSinks int
+}
+
+type analyzer struct {
+	source  []byte
+	tainted map[string]bool
+}
+
+// Analyze runs the data flow analysis for a function node
+func Analyze(function *sitter.Node, source []byte, language types.Language, catalog sinks.Catalog) *Result {
+	result := &Result{Tainted: []string{}}
+	if function == nil || source == nil {
+		return result
+	}
+	body := function.ChildByFieldName("body")
+	if body == nil {
+		return result
+	}
+
+	a := &analyzer{source: source, tainted: map[string]bool{}}
+	// types are no identifiers (but type_identifier, predefined_type, ...), so all identifiers are parameter names
+	if params := parameters(function); params != nil {
+		a.walk(params, func(n *sitter.Node) {
+			if n.Type() == "identifier" || n.Type() == "shorthand_property_identifier_pattern" {
+				a.taint(n.Content(source))
+			}
+		})
+	}
+
+	// propagate until no new variable gets tainted
+	assignments := a.assignments(body)
+	for changed := true; changed; {
+		changed = false
+		for _, as := range assignments {
+			if !a.references(as.value) {
+				continue
+			}
+			for _, name := range as.names {
+				if a.taint(name) {
+					changed = true
+				}
+			}
+		}
+	}
+
+	a.walk(body, func(n *sitter.Node) {
+		switch {
+		case slices.Contains(branchTypes, n.Type()):
+			result.Branches++
+			if a.referencesCondition(n) {
+				result.ParamBranches++
+			}
+		case slices.Contains(loopTypes, n.Type()):
+			result.Branches++
+			if a.referencesCondition(n) {
+				result.ParamBranches++
+				result.TaintedLoops++
+			}
+		case slices.Contains(indexTypes, n.Type()):
+			if a.referencesIndex(n) {
+				result.TaintedIndexes++
+			}
+		}
+	})
+
+	for _, call := range catalog.Calls(body, source, language) {
+		result.SinkCalls++
+		if a.references(call.Node.ChildByFieldName("arguments")) {
+			result.TaintedSinks++
+		}
+	}
+
+	for name := range a.tainted {
+		result.Tainted = append(result.Tainted, name)
+	}
+	sort.Strings(result.Tainted)
+	return result
+}
+
+// marks a variable as tainted, returns false if it was already tainted or is the blank identifier
+func (a *analyzer) taint(name string) bool {
+	if name == "_" || a.tainted[name] {
+		return false
+	}
+	a.tainted[name] = true
+	return true
+}
+
+// returns the parameter list of a function, for c it is part of the declarator
+func parameters(function *sitter.Node) *sitter.Node {
+	if params := function.ChildByFieldName("parameters"); params != nil {
+		return params
+	}
+	for declarator := function.ChildByFieldName("declarator"); declarator != nil; declarator = declarator.ChildByFieldName("declarator") {
+		if declarator.Type() == "function_declarator" {
+			return declarator.ChildByFieldName("parameters")
+		}
+	}
+	return nil
+}
+
+// assignment of a value to one or more variables
+type assignment struct {
+	names []string
+	value *sitter.Node
+}
+
+// collects all assignments and declarations with a value
+func (a *analyzer) assignments(body *sitter.Node) []*assignment {
+	assignments := []*assignment{}
+	add := func(target, value *sitter.Node) {
+		if target == nil || value == nil {
+			return
+		}
+		names := []string{}
+		if target.Type() == "expression_list" {
+			for i := 0; i < int(target.NamedChildCount()); i++ {
+				if name := a.baseName(target.NamedChild(i)); name != "" {
+					names = append(names, name)
+				}
+			}
+		} else if name := a.baseName(target); name != "" {
+			names = append(names, name)
+		}
+		assignments = append(assignments, &assignment{names: names, value: value})
+	}
+
+	a.walk(body, func(n *sitter.Node) {
+		switch n.Type() {
+		case "short_var_declaration", "assignment_statement", "range_clause",
+			"assignment_expression", "augmented_assignment_expression", "for_in_statement":
+			add(n.ChildByFieldName("left"), n.ChildByFieldName("right"))
+		case "variable_declarator", "enhanced_for_statement":
+			add(n.ChildByFieldName("name"), n.ChildByFieldName("value"))
+		case "init_declarator":
+			add(n.ChildByFieldName("declarator"), n.ChildByFieldName("value"))
+		case "var_spec":
+			value := n.ChildByFieldName("value")
+			for i := 0; i < int(n.NamedChildCount()); i++ {
+				if child := n.NamedChild(i); child.Type() == "identifier" {
+					add(child, value)
+				}
+			}
+		}
+	})
+	return assignments
+}
+
+// returns the name of the variable written by an assignment target (eg. "buf" for buf[i] or *buf)
+func (a *analyzer) baseName(node *sitter.Node) string {
+	if node == nil {
+		return ""
+	}
+	if node.Type() == "identifier" {
+		return node.Content(a.source)
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if name := a.baseName(node.NamedChild(i)); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// checks if a node references a tainted variable
+func (a *analyzer) references(node *sitter.Node) bool {
+	if node == nil {
+		return false
+	}
+	if node.Type() == "identifier" || node.Type() == "shorthand_property_identifier" {
+		return a.tainted[node.Content(a.source)]
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if a.references(node.NamedChild(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+// checks if the condition of a branch or loop references a tainted variable
+func (a *analyzer) referencesCondition(node *sitter.Node) bool {
+	if c := condition(node); c != nil {
+		return a.references(c)
+	}
+
+	// go: switch without value, the values of the cases are the conditions
+	if node.Type() == "expression_switch_statement" {
+		for _, clause := range helper.ChildrenByType(node, "expression_case") {
+			if a.references(clause.ChildByFieldName("value")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checks if the index (or slice bounds) of an index expression references a tainted variable
+func (a *analyzer) referencesIndex(node *sitter.Node) bool {
+	for _, field := range []string{"index", "start", "end", "capacity"} {
+		if a.references(node.ChildByFieldName(field)) {
+			return true
+		}
+	}
+	return false
+}
+
+// calls visit for all named nodes below node (pre-order)
+func (a *analyzer) walk(node *sitter.Node, visit func(*sitter.Node)) {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		visit(child)
+		a.walk(child, visit)
+	}
+}
+
+// returns the node deciding a branch or loop
+func condition(node *sitter.Node) *sitter.Node {
+	for _, field := range []string{"condition", "value", "right"} {
+		if c := node.ChildByFieldName(field); c != nil {
+			return c
+		}
+	}
+
+	// go: the condition of a for loop is either a plain expression, a for_clause or a range_clause
+	if node.Type() == "for_statement" && node.NamedChildCount() > 1 {
+		header := node.NamedChild(0)
+		switch header.Type() {
+		case "for_clause":
+			return header.ChildByFieldName("condition")
+		case "range_clause":
+			return header.ChildByFieldName("right")
+		}
+		return header
+	}
+	return nil
+}
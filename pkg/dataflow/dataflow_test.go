@@ -0,0 +1,80 @@
+package dataflow_test
+
+import (
+	"testing"
+
+	"github.com/jochil/gcs/pkg/dataflow"
+	"github.com/jochil/gcs/pkg/parser"
+	"github.com/jochil/gcs/pkg/sinks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyze(t *testing.T) {
+	testCases := map[string]struct {
+		path     string
+		expected *dataflow.Result
+	}{
+		"go": {
+			path: "testdata/a.go",
+			expected: &dataflow.Result{
+				Tainted:        []string{"b", "count", "data", "limit", "n", "name", "size"},
+				Branches:       4,
+				ParamBranches:  4,
+				TaintedLoops:   2,
+				TaintedIndexes: 2,
+				SinkCalls:      2,
+				TaintedSinks:   1,
+			},
+		},
+		"java": {
+			path: "testdata/A.java",
+			expected: &dataflow.Result{
+				Tainted:        []string{"cmd", "input", "total", "v", "values"},
+				Branches:       4,
+				ParamBranches:  3,
+				TaintedLoops:   1,
+				TaintedIndexes: 1,
+				SinkCalls:      1,
+				TaintedSinks:   1,
+			},
+		},
+		"javascript": {
+			path: "testdata/a.js",
+			expected: &dataflow.Result{
+				Tainted:        []string{"depth", "input", "key", "obj"},
+				Branches:       1,
+				ParamBranches:  1,
+				TaintedIndexes: 1,
+				SinkCalls:      2,
+				TaintedSinks:   1,
+			},
+		},
+		"c": {
+			path: "testdata/a.c",
+			expected: &dataflow.Result{
+				Tainted:        []string{"buf", "input", "len", "p"},
+				Branches:       2,
+				ParamBranches:  2,
+				TaintedLoops:   1,
+				TaintedIndexes: 1,
+				SinkCalls:      1,
+				TaintedSinks:   1,
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			candidates, err := parser.ParseFile(tc.path)
+			require.NoError(t, err)
+			require.Len(t, candidates, 1)
+			c := candidates[0]
+			assert.Equal(t, tc.expected, dataflow.Analyze(c.AST, c.Source, c.Language, sinks.Default))
+		})
+	}
+}
+
+func TestAnalyze_Empty(t *testing.T) {
+	assert.Equal(t, &dataflow.Result{Tainted: []string{}}, dataflow.Analyze(nil, nil, 0, sinks.Default))
+}
@@ -0,0 +1,18 @@
+class A {
+    int a(String input, int[] values) {
+        int total = 0;
+        for (int v : values) {
+            total += v;
+        }
+        String cmd = input.trim();
+        if (cmd.isEmpty()) {
+            return values[total];
+        }
+        int other = 5;
+        while (other > 0) {
+            other--;
+        }
+        Runtime.getRuntime().exec(cmd);
+        return total > 0 ? 1 : 0;
+    }
+}
@@ -0,0 +1,10 @@
+int a(char *input, int len) {
+    char buf[16];
+    char *p = input;
+    for (int i = 0; i < len; i++) {
+        buf[i] = p[i];
+    }
+    buf[len % 16] = 0;
+    strcpy(buf, p);
+    return len > 16 ? 1 : 0;
+}
@@ -0,0 +1,28 @@
+package main
+
+import "os/exec"
+
+func A(data []byte, n int, name string) int {
+	size := len(data)
+	var limit = size * 2
+	count := 0
+	for i := 0; i < limit; i++ {
+		count++
+	}
+	if count > 10 {
+		return data[n]
+	}
+	switch {
+	case name == "":
+		return 0
+	}
+	for _, b := range data {
+		count += int(b)
+	}
+	fixed := []int{1, 2, 3}
+	_ = fixed[count]
+	_ = fixed[1]
+	exec.Command(name).Run()
+	exec.Command("ls").Run()
+	return count
+}
@@ -0,0 +1,8 @@
+function a(input, { depth }) {
+  const obj = JSON.parse(input);
+  let key = obj.key;
+  if (depth > 3) {
+    return eval("1 + 1");
+  }
+  return obj[key];
+}
@@ -0,0 +1,505 @@
+// Package expr evaluates simple arithmetic and boolean expressions over named numeric variables
+// (eg. "2*cc + 3*cog - 5*fuzz"). The expressions use the go syntax, booleans are represented as 1 and 0.
+//
+// Supported are number literals, true/false, variables, parentheses, the operators + - * / % ! < <= > >= == != && ||
+// and the functions min, max, abs, log, sqrt, pow and cond(condition, then, else).
+//
+// Filter expressions (see ParseFilter) additionally support string literals and qualified variables
+// (eg. `language == "Go" && metrics.cc >= 5`).
+package expr
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// Expression is a parsed expression
+type Expression struct {
+	source string
+	root   ast.Expr
+}
+
+// Variables are the values of a filter expression, supported are numbers, booleans and strings
+type Variables map[string]any
+
+// Type is the type of a value in a filter expression, booleans are numbers
+type Type int
+
+const (
+	Number Type = iota
+	String
+)
+
+func (t Type) String() string {
+	if t == String {
+		return "string"
+	}
+	return "number"
+}
+
+// Types are the types of the variables usable in a filter expression
+type Types map[string]Type
+
+// supported functions with their number of arguments (-1: at least one)
+var functions = map[string]int{
+	"min":  -1,
+	"max":  -1,
+	"abs":  1,
+	"log":  1,
+	"sqrt": 1,
+	"pow":  2,
+	"cond": 3,
+}
+
+var constants = map[string]float64{
+	"true":  1,
+	"false": 0,
+}
+
+// Parse parses an expression
+func Parse(source string) (*Expression, error) {
+	return parse(source, false)
+}
+
+// ParseFilter parses a filter expression, it supports string literals and qualified variables. Unknown
+// variables and type errors (eg. comparing a string with a number) are detected in every branch, regardless
+// of the short circuit evaluation.
+func ParseFilter(source string, types Types) (*Expression, error) {
+	e, err := parse(source, true)
+	if err != nil {
+		return nil, err
+	}
+	t, err := typeOf(e.root, types)
+	if err == nil && t != Number {
+		err = fmt.Errorf("expected a condition, got a %s", t)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	return e, nil
+}
+
+func parse(source string, filter bool) (*Expression, error) {
+	root, err := parser.ParseExpr(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	if err := validate(root, filter); err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// MustParse is like Parse but panics if the expression is invalid
+func MustParse(source string) *Expression {
+	e, err := Parse(source)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Variables returns the names of all variables used in the expression
+func (e *Expression) Variables() []string {
+	variables := []string{}
+	var visit func(ast.Expr)
+	visit = func(node ast.Expr) {
+		switch n := node.(type) {
+		case *ast.Ident, *ast.SelectorExpr:
+			name := qualifiedName(n)
+			if _, ok := constants[name]; !ok && !slices.Contains(variables, name) {
+				variables = append(variables, name)
+			}
+		case *ast.ParenExpr:
+			visit(n.X)
+		case *ast.UnaryExpr:
+			visit(n.X)
+		case *ast.BinaryExpr:
+			visit(n.X)
+			visit(n.Y)
+		case *ast.CallExpr:
+			for _, arg := range n.Args {
+				visit(arg)
+			}
+		}
+	}
+	visit(e.root)
+	return variables
+}
+
+// Check returns an error if the expression uses a variable not contained in the given list
+func (e *Expression) Check(known []string) error {
+	for _, v := range e.Variables() {
+		if !slices.Contains(known, v) {
+			return fmt.Errorf("unknown variable %s in expression %q", v, e.source)
+		}
+	}
+	return nil
+}
+
+// Eval evaluates the expression, missing variables are an error
+func (e *Expression) Eval(variables map[string]float64) (float64, error) {
+	val, err := eval(e.root, func(name string) (any, bool) {
+		val, ok := variables[name]
+		return val, ok
+	})
+	if err != nil {
+		return 0, err
+	}
+	return number(val)
+}
+
+// EvalBool evaluates the expression and returns true for every value except 0
+func (e *Expression) EvalBool(variables map[string]float64) (bool, error) {
+	val, err := e.Eval(variables)
+	return val != 0, err
+}
+
+// Match evaluates a filter expression and returns true for every value except 0, boolean variables are
+// represented as 1 and 0
+func (e *Expression) Match(variables Variables) (bool, error) {
+	val, err := eval(e.root, func(name string) (any, bool) {
+		val, ok := variables[name]
+		if !ok {
+			return nil, false
+		}
+		switch v := val.(type) {
+		case bool:
+			return boolean(v), true
+		case int:
+			return float64(v), true
+		case float64, string:
+			return v, true
+		}
+		return fmt.Sprint(val), true
+	})
+	if err != nil {
+		return false, err
+	}
+	n, err := number(val)
+	return n != 0, err
+}
+
+func validate(node ast.Expr, filter bool) error {
+	switch n := node.(type) {
+	case *ast.BasicLit:
+		if n.Kind != token.INT && n.Kind != token.FLOAT && (!filter || n.Kind != token.STRING) {
+			return fmt.Errorf("unsupported literal %s", n.Value)
+		}
+	case *ast.Ident:
+	case *ast.SelectorExpr:
+		if !filter || qualifiedName(n) == "" {
+			return fmt.Errorf("unsupported selector")
+		}
+	case *ast.ParenExpr:
+		return validate(n.X, filter)
+	case *ast.UnaryExpr:
+		if n.Op != token.ADD && n.Op != token.SUB && n.Op != token.NOT {
+			return fmt.Errorf("unsupported operator %s", n.Op)
+		}
+		return validate(n.X, filter)
+	case *ast.BinaryExpr:
+		if _, err := binary(n.Op, 0.0, 1.0); err != nil {
+			return err
+		}
+		if err := validate(n.X, filter); err != nil {
+			return err
+		}
+		return validate(n.Y, filter)
+	case *ast.CallExpr:
+		ident, ok := n.Fun.(*ast.Ident)
+		if !ok {
+			return fmt.Errorf("unsupported function call")
+		}
+		args, ok := functions[ident.Name]
+		if !ok {
+			return fmt.Errorf("unknown function %s", ident.Name)
+		}
+		if (args == -1 && len(n.Args) == 0) || (args != -1 && len(n.Args) != args) {
+			return fmt.Errorf("wrong number of arguments for %s", ident.Name)
+		}
+		for _, arg := range n.Args {
+			if err := validate(arg, filter); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported syntax %T", node)
+	}
+	return nil
+}
+
+// returns the type of a (validated) filter node, strings are only supported by comparisons
+func typeOf(node ast.Expr, types Types) (Type, error) {
+	switch n := node.(type) {
+	case *ast.BasicLit:
+		if n.Kind == token.STRING {
+			return String, nil
+		}
+		return Number, nil
+
+	case *ast.Ident, *ast.SelectorExpr:
+		name := qualifiedName(n)
+		if _, ok := constants[name]; ok {
+			return Number, nil
+		}
+		t, ok := types[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown variable %s", name)
+		}
+		return t, nil
+
+	case *ast.ParenExpr:
+		return typeOf(n.X, types)
+
+	case *ast.UnaryExpr:
+		if t, err := typeOf(n.X, types); err != nil || t != Number {
+			return 0, typeError(err, t, n.Op)
+		}
+		return Number, nil
+
+	case *ast.BinaryExpr:
+		x, err := typeOf(n.X, types)
+		if err != nil {
+			return 0, err
+		}
+		y, err := typeOf(n.Y, types)
+		if err != nil {
+			return 0, err
+		}
+		if x != y {
+			return 0, fmt.Errorf("mismatched types %s %s %s", x, n.Op, y)
+		}
+		if x == String {
+			if _, err := compare(n.Op, "", ""); err != nil {
+				return 0, err
+			}
+		}
+		return Number, nil
+
+	case *ast.CallExpr:
+		for _, arg := range n.Args {
+			if t, err := typeOf(arg, types); err != nil || t != Number {
+				return 0, typeError(err, t, n.Fun.(*ast.Ident).Name)
+			}
+		}
+		return Number, nil
+	}
+	return 0, fmt.Errorf("unsupported syntax %T", node)
+}
+
+// returns the given error or an error for a string used as number
+func typeError(err error, t Type, usage any) error {
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("unsupported %s argument for %s", t, usage)
+}
+
+// evaluates a node to a float64 or string value
+func eval(node ast.Expr, lookup func(name string) (any, bool)) (any, error) {
+	switch n := node.(type) {
+	case *ast.BasicLit:
+		if n.Kind == token.STRING {
+			return strconv.Unquote(n.Value)
+		}
+		return strconv.ParseFloat(n.Value, 64)
+
+	case *ast.Ident, *ast.SelectorExpr:
+		name := qualifiedName(n)
+		if val, ok := constants[name]; ok {
+			return val, nil
+		}
+		val, ok := lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown variable %s", name)
+		}
+		return val, nil
+
+	case *ast.ParenExpr:
+		return eval(n.X, lookup)
+
+	case *ast.UnaryExpr:
+		val, err := eval(n.X, lookup)
+		if err != nil {
+			return nil, err
+		}
+		x, err := number(val)
+		if err != nil {
+			return nil, err
+		}
+		switch n.Op {
+		case token.SUB:
+			return -x, nil
+		case token.NOT:
+			return boolean(x == 0), nil
+		}
+		return x, nil
+
+	case *ast.BinaryExpr:
+		x, err := eval(n.X, lookup)
+		if err != nil {
+			return nil, err
+		}
+		// short circuit evaluation
+		if n.Op == token.LAND || n.Op == token.LOR {
+			cond, err := number(x)
+			if err != nil {
+				return nil, err
+			}
+			if n.Op == token.LAND && cond == 0 {
+				return 0.0, nil
+			}
+			if n.Op == token.LOR && cond != 0 {
+				return 1.0, nil
+			}
+		}
+		y, err := eval(n.Y, lookup)
+		if err != nil {
+			return nil, err
+		}
+		return binary(n.Op, x, y)
+
+	case *ast.CallExpr:
+		args := []float64{}
+		for _, arg := range n.Args {
+			val, err := eval(arg, lookup)
+			if err != nil {
+				return nil, err
+			}
+			x, err := number(val)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, x)
+		}
+		return call(n.Fun.(*ast.Ident).Name, args)
+	}
+	return nil, fmt.Errorf("unsupported syntax %T", node)
+}
+
+// applies an operator to two numbers or compares two strings
+func binary(op token.Token, x, y any) (any, error) {
+	xs, xString := x.(string)
+	ys, yString := y.(string)
+	if xString != yString {
+		return nil, fmt.Errorf("mismatched types %T %s %T", x, op, y)
+	}
+	if xString {
+		return compare(op, xs, ys)
+	}
+	return arithmetic(op, x.(float64), y.(float64))
+}
+
+func compare(op token.Token, x, y string) (float64, error) {
+	switch op {
+	case token.LSS:
+		return boolean(x < y), nil
+	case token.LEQ:
+		return boolean(x <= y), nil
+	case token.GTR:
+		return boolean(x > y), nil
+	case token.GEQ:
+		return boolean(x >= y), nil
+	case token.EQL:
+		return boolean(x == y), nil
+	case token.NEQ:
+		return boolean(x != y), nil
+	}
+	return 0, fmt.Errorf("unsupported operator %s for strings", op)
+}
+
+func arithmetic(op token.Token, x, y float64) (float64, error) {
+	switch op {
+	case token.ADD:
+		return x + y, nil
+	case token.SUB:
+		return x - y, nil
+	case token.MUL:
+		return x * y, nil
+	case token.QUO:
+		return x / y, nil
+	case token.REM:
+		return math.Mod(x, y), nil
+	case token.LSS:
+		return boolean(x < y), nil
+	case token.LEQ:
+		return boolean(x <= y), nil
+	case token.GTR:
+		return boolean(x > y), nil
+	case token.GEQ:
+		return boolean(x >= y), nil
+	case token.EQL:
+		return boolean(x == y), nil
+	case token.NEQ:
+		return boolean(x != y), nil
+	case token.LAND:
+		return boolean(x != 0 && y != 0), nil
+	case token.LOR:
+		return boolean(x != 0 || y != 0), nil
+	}
+	return 0, fmt.Errorf("unsupported operator %s", op)
+}
+
+func call(name string, args []float64) (float64, error) {
+	switch name {
+	case "min":
+		return slices.Min(args), nil
+	case "max":
+		return slices.Max(args), nil
+	case "abs":
+		return math.Abs(args[0]), nil
+	case "log":
+		return math.Log(args[0]), nil
+	case "sqrt":
+		return math.Sqrt(args[0]), nil
+	case "pow":
+		return math.Pow(args[0], args[1]), nil
+	case "cond":
+		if args[0] != 0 {
+			return args[1], nil
+		}
+		return args[2], nil
+	}
+	return 0, fmt.Errorf("unknown function %s", name)
+}
+
+func boolean(val bool) float64 {
+	if val {
+		return 1
+	}
+	return 0
+}
+
+// returns the value as number, strings are only supported by comparisons
+func number(val any) (float64, error) {
+	x, ok := val.(float64)
+	if !ok {
+		return 0, fmt.Errorf("expected a number, got %q", val)
+	}
+	return x, nil
+}
+
+// returns the name of an identifier or a qualified identifier (eg. "metrics.cc"), an empty string for
+// other expressions
+func qualifiedName(node ast.Expr) string {
+	switch n := node.(type) {
+	case *ast.Ident:
+		return n.Name
+	case *ast.SelectorExpr:
+		if x := qualifiedName(n.X); x != "" {
+			return x + "." + n.Sel.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,174 @@
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/jochil/gcs/pkg/expr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEval(t *testing.T) {
+	variables := map[string]float64{"cc": 0.5, "cog": 1, "fuzz": 1, "unit": 0}
+
+	testCases := map[string]struct {
+		expression string
+		expected   float64
+	}{
+		"literal":     {expression: "4.5", expected: 4.5},
+		"weighted":    {expression: "2*cc + 3*cog - 5*fuzz", expected: -1},
+		"parentheses": {expression: "(cc + cog) * 2", expected: 3},
+		"unary":       {expression: "-cc + +cog", expected: 0.5},
+		"modulo":      {expression: "5 % 3", expected: 2},
+		"comparison":  {expression: "cc < cog", expected: 1},
+		"equal":       {expression: "unit == 0", expected: 1},
+		"not":         {expression: "!fuzz", expected: 0},
+		"and":         {expression: "fuzz && unit", expected: 0},
+		"or":          {expression: "fuzz || unit", expected: 1},
+		"bool":        {expression: "true && !false", expected: 1},
+		"min":         {expression: "min(cc, cog, 2)", expected: 0.5},
+		"max":         {expression: "max(cc, cog)", expected: 1},
+		"abs":         {expression: "abs(cc - cog)", expected: 0.5},
+		"pow":         {expression: "pow(2, 3)", expected: 8},
+		"sqrt":        {expression: "sqrt(16)", expected: 4},
+		"cond":        {expression: "cond(fuzz, 0, cog * 10)", expected: 0},
+		"short":       {expression: "unit && missing", expected: 0},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			e, err := expr.Parse(tc.expression)
+			require.NoError(t, err)
+			val, err := e.Eval(variables)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, val)
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	testCases := []string{
+		"2 *",
+		`"string"`,
+		"a.b",
+		"a[1]",
+		"foo(1)",
+		"min()",
+		"pow(1)",
+		"1 << 2",
+		"^a",
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc, func(t *testing.T) {
+			_, err := expr.Parse(tc)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestVariables(t *testing.T) {
+	e := expr.MustParse("2*cc + max(cog, cc) - cond(fuzz, 1, 0) * true")
+	assert.Equal(t, []string{"cc", "cog", "fuzz"}, e.Variables())
+
+	require.NoError(t, e.Check([]string{"cc", "cog", "fuzz", "unit"}))
+	require.Error(t, e.Check([]string{"cc", "cog"}))
+
+	_, err := e.Eval(map[string]float64{"cc": 1})
+	require.Error(t, err)
+}
+
+func TestEvalBool(t *testing.T) {
+	e := expr.MustParse("cc > 0.5 && loc >= 10")
+
+	ok, err := e.EvalBool(map[string]float64{"cc": 0.6, "loc": 10})
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = e.EvalBool(map[string]float64{"cc": 0.6, "loc": 9})
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestMatch(t *testing.T) {
+	variables := expr.Variables{
+		"language":      "Go",
+		"visibility":    "public",
+		"has_fuzz_test": false,
+		"params":        2,
+		"metrics.cc":    7.0,
+	}
+	types := expr.Types{
+		"language":      expr.String,
+		"visibility":    expr.String,
+		"has_fuzz_test": expr.Number,
+		"params":        expr.Number,
+		"metrics.cc":    expr.Number,
+	}
+
+	testCases := map[string]struct {
+		expression string
+		expected   bool
+	}{
+		"string":    {expression: `language == "Go"`, expected: true},
+		"not_equal": {expression: `visibility != "public"`, expected: false},
+		"selector":  {expression: "metrics.cc >= 5", expected: true},
+		"bool":      {expression: "!has_fuzz_test", expected: true},
+		"int":       {expression: "params > 2", expected: false},
+		"combined":  {expression: `language == "Go" && visibility == "public" && metrics.cc >= 5 && !has_fuzz_test`, expected: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			e, err := expr.ParseFilter(tc.expression, types)
+			require.NoError(t, err)
+			ok, err := e.Match(variables)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ok)
+		})
+	}
+}
+
+func TestMatch_Invalid(t *testing.T) {
+	// the value does not match the declared type
+	e, err := expr.ParseFilter("language > 1", expr.Types{"language": expr.Number})
+	require.NoError(t, err)
+	_, err = e.Match(expr.Variables{"language": "Go"})
+	require.Error(t, err)
+}
+
+func TestParseFilter(t *testing.T) {
+	types := expr.Types{"metrics.cc": expr.Number, "name": expr.String}
+	e, err := expr.ParseFilter(`metrics.cc > 1 && name == "parse"`, types)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"metrics.cc", "name"}, e.Variables())
+
+	_, err = expr.ParseFilter("f(1).cc > 1", types)
+	require.Error(t, err)
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	types := expr.Types{"language": expr.String, "cc": expr.Number}
+
+	testCases := []string{
+		`language == 1`,
+		`language + "x" == "Gox"`,
+		`!language`,
+		`language && true`,
+		`max(language, 1) > 0`,
+		`language`,
+		`"Go"`,
+		`metrics.cc > 1`,
+		// branches skipped by the short circuit evaluation are checked as well
+		`false && language > 5`,
+		`true || cc == "high"`,
+		`cond(cc > 1, 1, missing) > 0`,
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc, func(t *testing.T) {
+			_, err := expr.ParseFilter(tc, types)
+			require.Error(t, err)
+		})
+	}
+}
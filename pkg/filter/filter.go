@@ -8,7 +8,13 @@ import (
 	"github.com/jochil/gcs/pkg/helper"
 )
 
+// Valid checks if a file is a supported source file containing candidates
 func Valid(path string, includedExtensions []string) bool {
+	return Supported(path, includedExtensions) && !IsTest(path)
+}
+
+// Supported checks if the language of a file is supported and the extension is included
+func Supported(path string, includedExtensions []string) bool {
 	ext := filepath.Ext(path)
 
 	if len(includedExtensions) > 0 && !slices.Contains(includedExtensions, ext) {
@@ -20,10 +26,25 @@ func Valid(path string, includedExtensions []string) bool {
 		return false
 	}
 
-	// filter go tests
-	if strings.HasSuffix(path, "_test.go") {
-		return false
-	}
-
 	return true
 }
+
+// IsTest checks if a file contains tests (go tests, junit/jazzer tests, jest/mocha tests), the path should be
+// relative to the scanned directory as all its directories are checked (eg. src/test)
+func IsTest(path string) bool {
+	path = filepath.ToSlash(path)
+	base := filepath.Base(path)
+
+	switch filepath.Ext(path) {
+	case ".go":
+		return strings.HasSuffix(base, "_test.go")
+	case ".java":
+		return strings.Contains("/"+path, "/src/test/") ||
+			strings.HasSuffix(base, "Test.java") ||
+			strings.HasSuffix(base, "Tests.java") ||
+			strings.HasSuffix(base, "Fuzzer.java")
+	case ".js", ".ts":
+		return strings.Contains(base, ".test.") || strings.Contains(base, ".spec.")
+	}
+	return false
+}
@@ -19,6 +19,9 @@ func TestFilter(t *testing.T) {
 		"java":    {path: "foo.java", extensions: []string{}, result: true},
 		"js":      {path: "foo.js", extensions: []string{}, result: true},
 		"c":       {path: "foo.c", extensions: []string{}, result: true},
+		"js_test": {path: "foo.test.js", extensions: []string{}, result: false},
+		"ts_spec": {path: "foo.spec.ts", extensions: []string{}, result: false},
+		"junit":   {path: "src/test/java/FooTest.java", extensions: []string{}, result: false},
 	}
 
 	for name, tc := range tests {
@@ -29,3 +32,28 @@ func TestFilter(t *testing.T) {
 	}
 
 }
+
+func TestIsTest(t *testing.T) {
+	tests := map[string]struct {
+		path   string
+		result bool
+	}{
+		"go":          {path: "foo.go", result: false},
+		"go_test":     {path: "foo_test.go", result: true},
+		"java":        {path: "src/main/java/Foo.java", result: false},
+		"java_test":   {path: "FooTest.java", result: true},
+		"java_fuzzer": {path: "FooFuzzer.java", result: true},
+		"java_src":    {path: "src/test/java/Helper.java", result: true},
+		"js":          {path: "foo.js", result: false},
+		"js_test":     {path: "foo.test.js", result: true},
+		"ts_spec":     {path: "foo.spec.ts", result: true},
+		"c":           {path: "foo_test.c", result: false},
+		"unsupported": {path: "foo_test.py", result: false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.result, filter.IsTest(tc.path))
+		})
+	}
+}
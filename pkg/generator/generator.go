@@ -5,7 +5,8 @@ import (
 	"github.com/jochil/gcs/pkg/types"
 )
 
-func Render(c *candidate.Candidate) string {
+// Render generates a test for the candidate, an empty string is returned for unsupported languages
+func Render(c *candidate.Candidate) (string, error) {
 	switch c.Language {
 	case types.Go:
 		return renderGoUnitTest(c)
@@ -13,5 +14,5 @@ func Render(c *candidate.Candidate) string {
 		return renderJavaFuzzTest(c)
 	}
 
-	return ""
+	return "", nil
 }
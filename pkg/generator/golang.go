@@ -3,7 +3,6 @@ package generator
 import (
 	"bytes"
 	"fmt"
-	"log/slog"
 	"strings"
 
 	"github.com/dave/jennifer/jen"
@@ -11,7 +10,7 @@ import (
 )
 
 // CreateGoTest generates the test source code for a given candidate
-func renderGoUnitTest(c *candidate.Candidate) string {
+func renderGoUnitTest(c *candidate.Candidate) (string, error) {
 	// TODO add package to candidate
 	goPackage := "foo"
 
@@ -93,9 +92,8 @@ func renderGoUnitTest(c *candidate.Candidate) string {
 		Block(block...)
 
 	buf := &bytes.Buffer{}
-	err := f.Render(buf)
-	if err != nil {
-		slog.Error("unable to render function", "func", c.Function.Name)
+	if err := f.Render(buf); err != nil {
+		return "", fmt.Errorf("unable to render test for %s: %w", c.Function.Name, err)
 	}
-	return buf.String()
+	return buf.String(), nil
 }
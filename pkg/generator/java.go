@@ -4,7 +4,6 @@ import (
 	"bytes"
 	_ "embed"
 	"fmt"
-	"log/slog"
 	"strings"
 	"text/template"
 
@@ -14,24 +13,22 @@ import (
 //go:embed tmpl/java.tmpl
 var javaTemplate []byte
 
-func renderJavaFuzzTest(c *candidate.Candidate) string {
+func renderJavaFuzzTest(c *candidate.Candidate) (string, error) {
 	tmpl, err := template.New("java").Funcs(template.FuncMap{
 		"renderParamsAsVar": renderParamsAsVar,
 		"renderClassInit":   renderClassInit,
 		"renderMethodCall":  renderMethodCall,
 	}).Parse(string(javaTemplate))
 	if err != nil {
-		slog.Error("unable to load template", "err", err.Error())
-		panic(err)
+		return "", fmt.Errorf("unable to load template: %w", err)
 	}
 
 	var out bytes.Buffer
 	err = tmpl.Execute(&out, c)
 	if err != nil {
-		slog.Error("unable to render template", "err", err.Error())
-		panic(err)
+		return "", fmt.Errorf("unable to render template for %s: %w", c.Function.Name, err)
 	}
-	return out.String()
+	return out.String(), nil
 }
 
 func renderObjVar(class string) string {
@@ -0,0 +1,155 @@
+package helper
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jochil/gcs/pkg/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// GoImports returns the import paths of the go file containing the node indexed by their package name
+func GoImports(node *sitter.Node, source []byte) map[string]string {
+	root := node
+	for root.Parent() != nil {
+		root = root.Parent()
+	}
+
+	imports := map[string]string{}
+	var visit func(*sitter.Node)
+	visit = func(n *sitter.Node) {
+		if n.Type() == "import_spec" {
+			path := strings.Trim(n.ChildByFieldName("path").Content(source), "\"`")
+			name := ""
+			if alias := n.ChildByFieldName("name"); alias != nil {
+				name = alias.Content(source)
+			} else {
+				name = PackageName(path)
+			}
+			imports[name] = path
+			return
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			child := n.NamedChild(i)
+			if child.Type() == "import_declaration" || child.Type() == "import_spec_list" || child.Type() == "import_spec" {
+				visit(child)
+			}
+		}
+	}
+	visit(root)
+	return imports
+}
+
+// PackageName returns the default package name of an import path, major version suffixes are skipped (eg. yaml.v3, go-git/v5)
+func PackageName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+	if i := strings.Index(name, ".v"); i != -1 {
+		name = name[:i]
+	}
+	return name
+}
+
+// Imports returns the imports of the file containing the node indexed by the name used in the code: go import
+// paths, java classes (eg. "java.util.List") and javascript/typescript module specifiers
+func Imports(node *sitter.Node, source []byte, language types.Language) map[string]string {
+	switch language {
+	case types.Go:
+		return GoImports(node, source)
+	case types.Java, types.JavaScript, types.TypeScript:
+	default:
+		return map[string]string{}
+	}
+
+	root := node
+	for root.Parent() != nil {
+		root = root.Parent()
+	}
+	imports := map[string]string{}
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		child := root.NamedChild(i)
+		switch child.Type() {
+		case "import_declaration":
+			// java: import a.b.C; static and wildcard imports are ignored
+			if child.NamedChildCount() != 1 || child.Child(1).Type() == "static" {
+				continue
+			}
+			path := child.NamedChild(0).Content(source)
+			imports[path[strings.LastIndex(path, ".")+1:]] = path
+		case "import_statement":
+			// import x from "m", import * as x from "m", import {x, y as z} from "m"
+			module := child.ChildByFieldName("source")
+			if module == nil {
+				continue
+			}
+			for _, name := range bindings(child, source) {
+				imports[name] = unquote(module.Content(source))
+			}
+		case "lexical_declaration", "variable_declaration":
+			// const x = require("m"), const {x, y} = require("m")
+			for j := 0; j < int(child.NamedChildCount()); j++ {
+				declarator := child.NamedChild(j)
+				value := declarator.ChildByFieldName("value")
+				if value == nil || value.Type() != "call_expression" || value.ChildByFieldName("function").Content(source) != "require" {
+					continue
+				}
+				args := value.ChildByFieldName("arguments")
+				if args == nil || args.NamedChildCount() == 0 || args.NamedChild(0).Type() != "string" {
+					continue
+				}
+				for _, name := range bindings(declarator.ChildByFieldName("name"), source) {
+					imports[name] = unquote(args.NamedChild(0).Content(source))
+				}
+			}
+		}
+	}
+	return imports
+}
+
+// returns the names bound by an import clause or a destructuring pattern
+func bindings(node *sitter.Node, source []byte) []string {
+	names := []string{}
+	if node == nil {
+		return names
+	}
+	var visit func(*sitter.Node)
+	visit = func(n *sitter.Node) {
+		switch n.Type() {
+		case "identifier", "shorthand_property_identifier_pattern":
+			names = append(names, n.Content(source))
+			return
+		case "import_specifier":
+			if alias := n.ChildByFieldName("alias"); alias != nil {
+				names = append(names, alias.Content(source))
+			} else if name := n.ChildByFieldName("name"); name != nil {
+				names = append(names, name.Content(source))
+			}
+			return
+		case "string":
+			return
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			visit(n.NamedChild(i))
+		}
+	}
+	visit(node)
+	return names
+}
+
+func unquote(s string) string {
+	return strings.Trim(s, "\"'`")
+}
+
+// ImportsFile checks if a javascript/typescript module specifier imported by the file importer refers to the file
+// at path, only relative specifiers refer to files of the project
+func ImportsFile(importer, specifier, path string) bool {
+	if !strings.HasPrefix(specifier, ".") {
+		return false
+	}
+	module := filepath.Join(filepath.Dir(importer), filepath.FromSlash(specifier))
+	file := strings.TrimSuffix(path, filepath.Ext(path))
+	return file == module || file == filepath.Join(module, "index")
+}
@@ -1,8 +1,9 @@
 package helper
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
-	"os"
 	"path/filepath"
 
 	"github.com/jochil/gcs/pkg/types"
@@ -32,17 +33,18 @@ var SitterLanguages = map[types.Language]*sitter.Language{
 	types.C:          c.GetLanguage(),
 }
 
+// ErrUnsupportedLanguage is returned for files with an unsupported extension
+var ErrUnsupportedLanguage = errors.New("unsupported language")
+
 // GuessLanguage returns the tree-sitter language for
 // supported languages (based on file extension)
-func GuessLanguage(path string) (string, types.Language) {
+func GuessLanguage(path string) (types.Language, error) {
 	ext := filepath.Ext(path)
 	slog.Info("guess language", "path", path, "ext", ext)
 
-	if language, ok := SupportedExt[ext]; ok {
-		return path, language
-	} else {
-		slog.Error("unable to guess language", "path", path)
-		os.Exit(1)
-		return "", 0
+	language, ok := SupportedExt[ext]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedLanguage, path)
 	}
+	return language, nil
 }
@@ -0,0 +1,48 @@
+package helper
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Workers returns the number of workers for the given number of jobs, values < 1 select GOMAXPROCS
+func Workers(jobs int) int {
+	if jobs < 1 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return jobs
+}
+
+// Parallel calls a function for the indexes 0 to n-1 on a bounded number of workers. The init function is
+// called once per worker and returns the function processing a single index, so every worker can reuse
+// its own state (eg. a tree-sitter parser). Writing the results by index keeps the output deterministic.
+func Parallel(n, workers int, init func() func(i int)) {
+	workers = min(Workers(workers), n)
+	if workers <= 1 {
+		if n > 0 {
+			process := init()
+			for i := 0; i < n; i++ {
+				process(i)
+			}
+		}
+		return
+	}
+
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			process := init()
+			for i := range indexes {
+				process(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
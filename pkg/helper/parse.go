@@ -0,0 +1,67 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Parse parses the source code with a (reused) tree-sitter parser, cancelling the context interrupts the parse and
+// its deadline is also set as operation limit. Only a cancellation during the parse is passed to
+// sitter.Parser.ParseCtx, since a context done after the parse can still set the cancellation flag of the parser,
+// which would break every following parse with the same parser.
+func Parse(ctx context.Context, parser *sitter.Parser, source []byte) (*sitter.Tree, error) {
+	if err := ContextErr(ctx); err != nil {
+		return nil, err
+	}
+	resetCancellation(parser)
+
+	limit := 0
+	if deadline, ok := ctx.Deadline(); ok {
+		limit = max(int(time.Until(deadline).Microseconds()), 1)
+	}
+	parser.SetOperationLimit(limit)
+
+	parseCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, cancel)
+	tree, err := parser.ParseCtx(parseCtx, nil, source)
+	stop()
+	if err != nil {
+		// otherwise the next call would resume the aborted parse
+		parser.Reset()
+		if errors.Is(err, sitter.ErrOperationLimit) {
+			return nil, context.DeadlineExceeded
+		}
+		if ctxErr := ContextErr(ctx); ctxErr != nil {
+			return nil, ctxErr
+		}
+	}
+	return tree, err
+}
+
+// canceled context without a done channel, see resetCancellation
+type canceledContext struct{ context.Context }
+
+func (canceledContext) Done() <-chan struct{} { return nil }
+func (canceledContext) Err() error            { return context.Canceled }
+
+// resets the cancellation flag possibly left by a context done right after the previous parse, ParseCtx resets
+// the flag if the parse was canceled and the context is done
+func resetCancellation(parser *sitter.Parser) {
+	if _, err := parser.ParseCtx(canceledContext{context.Background()}, nil, []byte{}); err != nil {
+		parser.Reset()
+	}
+}
+
+// ContextErr is like ctx.Err but also reports an exceeded deadline if the timer of the context has not fired yet
+func ContextErr(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"slices"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// node types increasing the cognitive complexity and the nesting level
+var cognitiveStructures = []string{
+	// loops
+	"for_statement",
+	"for_in_statement",
+	"enhanced_for_statement",
+	"while_statement",
+	"do_statement",
+	// switches
+	"switch_statement",
+	"switch_expression",
+	"expression_switch_statement",
+	"type_switch_statement",
+	"select_statement",
+	// ternary operators
+	"ternary_expression",
+	"conditional_expression",
+	// exception handling
+	"catch_clause",
+}
+
+// node types only increasing the nesting level (nested functions/lambdas)
+var cognitiveNestings = []string{
+	"func_literal",
+	"lambda_expression",
+	"arrow_function",
+	"function_expression",
+	"function",
+}
+
+type cognitiveCounter struct {
+	name       string
+	source     []byte
+	complexity int
+}
+
+// CalcCognitiveComplexity calculates the cognitive complexity as defined by SonarSource
+// (https://www.sonarsource.com/docs/CognitiveComplexity.pdf) based on the AST of a function.
+// The function name is used for detecting recursive calls.
+func CalcCognitiveComplexity(function *sitter.Node, name string, source []byte) int {
+	if function == nil {
+		return 0
+	}
+	body := function.ChildByFieldName("body")
+	if body == nil {
+		return 0
+	}
+
+	cc := &cognitiveCounter{name: name, source: source}
+	cc.visit(body, 0)
+	return cc.complexity
+}
+
+func (cc *cognitiveCounter) visit(node *sitter.Node, nesting int) {
+	if node == nil {
+		return
+	}
+
+	switch {
+	case node.Type() == "if_statement":
+		cc.visitIf(node, nesting, false)
+		return
+
+	case slices.Contains(cognitiveStructures, node.Type()):
+		cc.complexity += 1 + nesting
+		cc.visitChildren(node, nesting+1)
+		return
+
+	case slices.Contains(cognitiveNestings, node.Type()):
+		cc.visitChildren(node, nesting+1)
+		return
+
+	case node.Type() == "goto_statement":
+		cc.complexity++
+
+	case node.Type() == "break_statement", node.Type() == "continue_statement":
+		// only jumps to labels are counted
+		if node.NamedChildCount() > 0 {
+			cc.complexity++
+		}
+
+	case node.Type() == "binary_expression":
+		// every sequence of like logical operators is counted once
+		if op := operator(node); isLogical(op) && operator(node.Parent()) != op {
+			cc.complexity++
+		}
+
+	case node.Type() == "call_expression", node.Type() == "method_invocation":
+		if cc.isRecursive(node) {
+			cc.complexity++
+		}
+	}
+
+	cc.visitChildren(node, nesting)
+}
+
+// handles if/else if/else chains, only the first "if" gets a nesting increment
+func (cc *cognitiveCounter) visitIf(node *sitter.Node, nesting int, elseIf bool) {
+	if elseIf {
+		cc.complexity++
+	} else {
+		cc.complexity += 1 + nesting
+	}
+
+	cc.visit(node.ChildByFieldName("condition"), nesting)
+	cc.visit(node.ChildByFieldName("consequence"), nesting+1)
+
+	alternative := node.ChildByFieldName("alternative")
+	if alternative == nil {
+		return
+	}
+	// javascript/typescript: the alternative is wrapped by an else_clause
+	if alternative.Type() == "else_clause" {
+		alternative = alternative.NamedChild(0)
+	}
+
+	if alternative.Type() == "if_statement" {
+		cc.visitIf(alternative, nesting, true)
+	} else {
+		// else
+		cc.complexity++
+		cc.visit(alternative, nesting+1)
+	}
+}
+
+func (cc *cognitiveCounter) visitChildren(node *sitter.Node, nesting int) {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		cc.visit(node.NamedChild(i), nesting)
+	}
+}
+
+// checks if a call expression calls the function itself
+func (cc *cognitiveCounter) isRecursive(call *sitter.Node) bool {
+	if cc.source == nil || cc.name == "" {
+		return false
+	}
+
+	// java: method_invocation has a name field
+	callee := call.ChildByFieldName("name")
+	if callee == nil {
+		callee = call.ChildByFieldName("function")
+	}
+	if callee == nil {
+		return false
+	}
+
+	switch callee.Type() {
+	case "selector_expression":
+		// go: obj.Method()
+		callee = callee.ChildByFieldName("field")
+	case "member_expression":
+		// javascript: this.method()
+		callee = callee.ChildByFieldName("property")
+	}
+	return callee != nil && callee.Content(cc.source) == cc.name
+}
+
+func operator(node *sitter.Node) string {
+	if node == nil || node.Type() != "binary_expression" {
+		return ""
+	}
+	op := node.ChildByFieldName("operator")
+	if op == nil {
+		return ""
+	}
+	return op.Type()
+}
+
+func isLogical(op string) bool {
+	return op == "&&" || op == "||" || op == "??"
+}
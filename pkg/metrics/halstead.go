@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"math"
+	"slices"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// node types handled as a single operand even if they have child nodes (eg. quotes, escape sequences)
+var halsteadLiterals = []string{
+	"string",
+	"string_literal",
+	"interpreted_string_literal",
+	"raw_string_literal",
+	"template_string",
+	"char_literal",
+	"character_literal",
+	"rune_literal",
+}
+
+// Halstead holds the token counts needed for calculating the Halstead metrics
+type Halstead struct {
+	DistinctOperators int
+	DistinctOperands  int
+	Operators         int
+	Operands          int
+}
+
+// CountHalstead counts operators and operands in the tokens of a tree-sitter node.
+// Identifiers and literals are operands, all other tokens (keywords, operators, punctuation)
+// are operators. Comments are ignored.
+func CountHalstead(node *sitter.Node, source []byte) *Halstead {
+	h := &Halstead{}
+	if node == nil || source == nil {
+		return h
+	}
+
+	operators := map[string]bool{}
+	operands := map[string]bool{}
+
+	var visit func(*sitter.Node)
+	visit = func(n *sitter.Node) {
+		if strings.Contains(n.Type(), "comment") {
+			return
+		}
+
+		if n.IsNamed() && (n.ChildCount() == 0 || slices.Contains(halsteadLiterals, n.Type())) {
+			h.Operands++
+			operands[n.Content(source)] = true
+			return
+		}
+
+		if n.ChildCount() == 0 {
+			// skip (zero width) statement terminators
+			if n.StartByte() == n.EndByte() || strings.TrimSpace(n.Type()) == "" {
+				return
+			}
+			h.Operators++
+			operators[n.Type()] = true
+			return
+		}
+
+		for i := 0; i < int(n.ChildCount()); i++ {
+			visit(n.Child(i))
+		}
+	}
+	visit(node)
+
+	h.DistinctOperators = len(operators)
+	h.DistinctOperands = len(operands)
+	return h
+}
+
+// Vocabulary returns the number of distinct operators and operands
+func (h *Halstead) Vocabulary() int {
+	return h.DistinctOperators + h.DistinctOperands
+}
+
+// Length returns the total number of operators and operands
+func (h *Halstead) Length() int {
+	return h.Operators + h.Operands
+}
+
+// Volume returns the Halstead volume (N * log2(n))
+func (h *Halstead) Volume() float64 {
+	if h.Vocabulary() == 0 {
+		return 0
+	}
+	return float64(h.Length()) * math.Log2(float64(h.Vocabulary()))
+}
+
+// Difficulty returns the Halstead difficulty (n1/2 * N2/n2)
+func (h *Halstead) Difficulty() float64 {
+	if h.DistinctOperands == 0 {
+		return 0
+	}
+	return float64(h.DistinctOperators) / 2 * float64(h.Operands) / float64(h.DistinctOperands)
+}
+
+// Effort returns the Halstead effort (D * V)
+func (h *Halstead) Effort() float64 {
+	return h.Difficulty() * h.Volume()
+}
+
+// CalcMaintainabilityIndex calculates the maintainability index based on the Halstead volume,
+// cyclomatic complexity and lines of code. The result is normalized to a range of 0-100
+// (as done by Visual Studio), higher values are easier to maintain.
+func CalcMaintainabilityIndex(volume float64, cc int, loc int) float64 {
+	// avoid the undefined logarithm of 0 for empty functions
+	volume = math.Max(volume, 1)
+	lines := math.Max(float64(loc), 1)
+
+	mi := (171 - 5.2*math.Log(volume) - 0.23*float64(cc) - 16.2*math.Log(lines)) * 100 / 171
+	return math.Max(0, mi)
+}
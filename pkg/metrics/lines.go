@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"slices"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// node types containing a list of statements
+var statementLists = []string{
+	"block",
+	"statement_block",
+	"compound_statement",
+	"constructor_body",
+	"switch_block_statement_group",
+	"switch_case",
+	"switch_default",
+	"expression_case",
+	"type_case",
+	"default_case",
+	"communication_case",
+	"case_statement",
+}
+
+// Lines holds the different line counts of a function
+type Lines struct {
+	// Physical is the total amount of lines
+	Physical int
+	// Logical is the amount of statements
+	Logical int
+	// Comment is the amount of lines only containing comments
+	Comment int
+	// Blank is the amount of lines only containing whitespace
+	Blank int
+}
+
+// CountLines counts the physical, logical, comment and blank lines of a tree-sitter node
+func CountLines(node *sitter.Node, source []byte) Lines {
+	lines := Lines{}
+	if node == nil || source == nil {
+		return lines
+	}
+
+	first := node.StartPoint().Row
+	lines.Physical = int(node.EndPoint().Row-first) + 1
+
+	// mark every line as containing code and/or comments
+	code := make([]bool, lines.Physical)
+	comment := make([]bool, lines.Physical)
+	var visit func(*sitter.Node)
+	visit = func(n *sitter.Node) {
+		if strings.Contains(n.Type(), "comment") {
+			for row := n.StartPoint().Row; row <= n.EndPoint().Row; row++ {
+				comment[row-first] = true
+			}
+			return
+		}
+		if n.ChildCount() == 0 {
+			// skip (zero width) statement terminators
+			if n.StartByte() == n.EndByte() || strings.TrimSpace(n.Content(source)) == "" {
+				return
+			}
+			for row := n.StartPoint().Row; row <= n.EndPoint().Row; row++ {
+				code[row-first] = true
+			}
+			return
+		}
+		for i := 0; i < int(n.ChildCount()); i++ {
+			visit(n.Child(i))
+		}
+	}
+	visit(node)
+
+	for i := 0; i < lines.Physical; i++ {
+		if !code[i] && comment[i] {
+			lines.Comment++
+		} else if !code[i] {
+			lines.Blank++
+		}
+	}
+
+	lines.Logical = countStatements(node.ChildByFieldName("body"))
+
+	return lines
+}
+
+// counts all statements inside of statement lists (eg. blocks, case clauses, ...)
+func countStatements(node *sitter.Node) int {
+	if node == nil {
+		return 0
+	}
+
+	count := 0
+	isList := slices.Contains(statementLists, node.Type())
+	// case values are part of the case clause, but not a statement
+	value := node.ChildByFieldName("value")
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if isList && !isStatementList(child) && !strings.Contains(child.Type(), "comment") &&
+			child.Type() != "switch_label" && !sameNode(child, value) {
+			count++
+		}
+		count += countStatements(child)
+	}
+	return count
+}
+
+func isStatementList(node *sitter.Node) bool {
+	return slices.Contains(statementLists, node.Type())
+}
+
+func sameNode(a, b *sitter.Node) bool {
+	return a != nil && b != nil && a.StartByte() == b.StartByte() && a.EndByte() == b.EndByte() && a.Type() == b.Type()
+}
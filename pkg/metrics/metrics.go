@@ -5,35 +5,69 @@ import (
 	"log/slog"
 	"regexp"
 	"strings"
+	"time"
 
-	"github.com/dominikbraun/graph"
+	"github.com/jochil/gcs/pkg/cfg"
 	"github.com/jochil/gcs/pkg/types"
 )
 
 type Metrics struct {
 	LinesOfCode             int
+	LogicalLinesOfCode      int
+	CommentLines            int
+	BlankLines              int
 	CyclomaticComplexity    int
+	NPathComplexity         int
+	CognitiveComplexity     int
+	HalsteadVolume          float64
+	HalsteadDifficulty      float64
+	HalsteadEffort          float64
+	MaintainabilityIndex    float64
 	FuzzFriendlyName        bool
 	PrimitiveParametersOnly bool
+	ParameterCount          int
+	RawInputParameters      int
+	ReturnsError            bool
+	ReturnsValue            bool
+	Loops                   int
+	MaxLoopDepth            int
+	UnreachableBlocks       int
+	ChurnCommits            int
+	ChurnLinesChanged       int
+	ChurnAuthors            int
+	LastModified            time.Time
+	HasCoverage             bool
+	LineCoverage            float64
+	BranchCoverage          float64
+	HasUnitTest             bool
+	HasFuzzTest             bool
+	FanIn                   int
+	FanOut                  int
+	EntryPoints             int
+	Reachable               bool
+	// Sinks holds the number of calls per sink category (eg. exec, sql, memory)
+	Sinks     map[string]int
+	SinkCalls int
+	// data flow of the parameters (see dataflow.Analyze)
+	ParamBranches  int
+	TaintedLoops   int
+	TaintedIndexes int
+	TaintedSinks   int
+	// Custom holds the values of the registered metrics (see candidate.Register)
+	Custom map[string]float64
 }
 
-func CountLines(sourceCode string) int {
-	// TODO count actual lines.. no comments, no empty ones, ...
-	lines := strings.Split(strings.ReplaceAll(sourceCode, "\r\n", "\n"), "\n")
-	return len(lines)
-}
-
-func CalcCyclomaticComplexity(cfg graph.Graph[int, int]) (cc int, err error) {
-	if cfg == nil {
+func CalcCyclomaticComplexity(g cfg.Graph) (cc int, err error) {
+	if g == nil {
 		err = errors.New("no graph found")
 		return
 	}
 
-	edges, err := cfg.Size()
+	edges, err := g.Size()
 	if err != nil {
 		return
 	}
-	nodes, err := cfg.Order()
+	nodes, err := g.Order()
 	if err != nil {
 		return
 	}
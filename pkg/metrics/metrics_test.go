@@ -4,8 +4,10 @@ import (
 	"testing"
 
 	"github.com/jochil/gcs/pkg/metrics"
+	"github.com/jochil/gcs/pkg/parser"
 	"github.com/jochil/gcs/pkg/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHasFuzzFriedlyName(t *testing.T) {
@@ -50,3 +52,132 @@ func TestPrimitiveParametersOnly(t *testing.T) {
 		})
 	}
 }
+
+func TestCountRawInputParameters(t *testing.T) {
+	tests := map[string]struct {
+		types    []string
+		lang     types.Language
+		expected int
+	}{
+		"go":         {types: []string{"[]byte", "string", "io.Reader", "*bytes.Buffer", "...string", "int", "[]int", "*MyStruct"}, lang: types.Go, expected: 5},
+		"java":       {types: []string{"byte[]", "String", "InputStream", "BufferedReader", "char[]", "byte", "int[]", "MyClass"}, lang: types.Java, expected: 5},
+		"typescript": {types: []string{"string", "Buffer", "Uint8Array", "number", "object | null"}, lang: types.TypeScript, expected: 3},
+		"c":          {types: []string{"char*", "unsigned char[]", "uint8_t*", "void*", "char", "int*", "size_t"}, lang: types.C, expected: 4},
+		"javascript": {types: []string{"?"}, lang: types.JavaScript, expected: 0},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, metrics.CountRawInputParameters(tc.types, tc.lang))
+		})
+	}
+}
+
+func TestReturnsError(t *testing.T) {
+	assert.True(t, metrics.ReturnsError([]string{"[]byte", "error"}, nil, types.Go))
+	assert.False(t, metrics.ReturnsError([]string{"int"}, nil, types.Go))
+	assert.True(t, metrics.ReturnsError(nil, []string{"IOException"}, types.Java))
+	assert.False(t, metrics.ReturnsError([]string{"error"}, nil, types.Java))
+	assert.False(t, metrics.ReturnsError([]string{"int"}, nil, types.C))
+}
+
+func TestHasReturnValue(t *testing.T) {
+	candidates, err := parser.ParseFile("testdata/signature/returns.js")
+	require.NoError(t, err)
+	require.Len(t, candidates, 3)
+
+	expected := []bool{true, false, false}
+	for i, c := range candidates {
+		assert.Equal(t, expected[i], metrics.HasReturnValue(c.AST.ChildByFieldName("body")), c.Function.Name)
+	}
+}
+
+func TestCognitiveComplexity(t *testing.T) {
+	tests := map[string]struct {
+		path       string
+		complexity []int
+	}{
+		"go":         {path: "testdata/cognitive/cognitive.go", complexity: []int{7, 1}},
+		"java":       {path: "testdata/cognitive/Cognitive.java", complexity: []int{7, 3}},
+		"javascript": {path: "testdata/cognitive/cognitive.js", complexity: []int{3}},
+		"c":          {path: "testdata/cognitive/cognitive.c", complexity: []int{3}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			candidates, err := parser.ParseFile(tc.path)
+			require.NoError(t, err)
+			require.Len(t, candidates, len(tc.complexity))
+			for i, c := range candidates {
+				complexity := metrics.CalcCognitiveComplexity(c.AST, c.Function.Name, c.Source)
+				assert.Equal(t, tc.complexity[i], complexity, "wrong cognitive complexity for %s", c.Function.Name)
+			}
+		})
+	}
+}
+
+func TestHalstead(t *testing.T) {
+	tests := map[string]struct {
+		path     string
+		expected metrics.Halstead
+	}{
+		// operators: func ( , ) { return + } / operands: add a int b int int a b
+		"go": {path: "testdata/halstead/halstead.go", expected: metrics.Halstead{DistinctOperators: 8, DistinctOperands: 4, Operators: 8, Operands: 8}},
+		// operators: function ( ) { return + ; } / operands: greet name "hello \n" name
+		"javascript": {path: "testdata/halstead/halstead.js", expected: metrics.Halstead{DistinctOperators: 8, DistinctOperands: 3, Operators: 8, Operands: 4}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			candidates, err := parser.ParseFile(tc.path)
+			require.NoError(t, err)
+			require.Len(t, candidates, 1)
+			h := metrics.CountHalstead(candidates[0].AST, candidates[0].Source)
+			assert.Equal(t, tc.expected, *h)
+		})
+	}
+}
+
+func TestHalstead_Metrics(t *testing.T) {
+	h := &metrics.Halstead{DistinctOperators: 8, DistinctOperands: 4, Operators: 8, Operands: 8}
+	assert.Equal(t, 12, h.Vocabulary())
+	assert.Equal(t, 16, h.Length())
+	assert.InDelta(t, 57.36, h.Volume(), 0.01)
+	assert.InDelta(t, 8, h.Difficulty(), 0.01)
+	assert.InDelta(t, 458.88, h.Effort(), 0.01)
+
+	empty := &metrics.Halstead{}
+	assert.Zero(t, empty.Volume())
+	assert.Zero(t, empty.Difficulty())
+}
+
+func TestMaintainabilityIndex(t *testing.T) {
+	assert.InDelta(t, 77.14, metrics.CalcMaintainabilityIndex(57.36, 1, 3), 0.01)
+	assert.InDelta(t, 100, metrics.CalcMaintainabilityIndex(0, 0, 0), 0.2)
+	// huge functions are capped at 0
+	assert.Zero(t, metrics.CalcMaintainabilityIndex(1e9, 500, 10000))
+}
+
+func TestCountLines(t *testing.T) {
+	tests := map[string]struct {
+		path     string
+		expected metrics.Lines
+	}{
+		// statements: b := ..., if, b = 10, switch, b++, return
+		"go": {path: "testdata/lines/lines.go", expected: metrics.Lines{Physical: 17, Logical: 6, Comment: 4, Blank: 2}},
+		// java, javascript, typescript and c count the break statement as well
+		"java":       {path: "testdata/lines/Lines.java", expected: metrics.Lines{Physical: 18, Logical: 7, Comment: 4, Blank: 2}},
+		"javascript": {path: "testdata/lines/lines.js", expected: metrics.Lines{Physical: 18, Logical: 7, Comment: 4, Blank: 2}},
+		"typescript": {path: "testdata/lines/lines.ts", expected: metrics.Lines{Physical: 18, Logical: 7, Comment: 4, Blank: 2}},
+		"c":          {path: "testdata/lines/lines.c", expected: metrics.Lines{Physical: 18, Logical: 7, Comment: 4, Blank: 2}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			candidates, err := parser.ParseFile(tc.path)
+			require.NoError(t, err)
+			require.Len(t, candidates, 1)
+			assert.Equal(t, tc.expected, metrics.CountLines(candidates[0].AST, candidates[0].Source))
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"regexp"
+	"slices"
+
+	"github.com/jochil/gcs/pkg/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// types of raw input data (bytes, strings, streams), javascript has no parameter types
+var rawInputTypes = map[types.Language]*regexp.Regexp{
+	types.Go:         regexp.MustCompile(`^(\.\.\.)?(\[\](byte|uint8|rune)|string|\*?(io\.(Reader|ReadCloser|ReadSeeker|ReaderAt)|bufio\.Reader|bytes\.(Buffer|Reader)|strings\.Reader))$`),
+	types.Java:       regexp.MustCompile(`^((byte|char)(\[\]|\.\.\.)|(String|CharSequence|ByteBuffer|CharBuffer|\w*InputStream|\w*Reader)(\.\.\.)?)$`),
+	types.TypeScript: regexp.MustCompile(`^(string|Buffer|Uint8Array|ArrayBuffer|DataView|Readable)$`),
+	types.C:          regexp.MustCompile(`^((un)?signed )?(char|wchar_t|uint8_t|int8_t|u_char|void)(\*|\[\])$`),
+}
+
+// CountRawInputParameters returns the number of parameters taking raw data like []byte, string or io.Reader
+func CountRawInputParameters(paramTypes []string, lang types.Language) int {
+	re, ok := rawInputTypes[lang]
+	if !ok {
+		return 0
+	}
+	count := 0
+	for _, t := range paramTypes {
+		if re.MatchString(t) {
+			count++
+		}
+	}
+	return count
+}
+
+// ReturnsError checks if a function returns an error (go) or declares thrown exceptions (java)
+func ReturnsError(returnTypes []string, throws []string, lang types.Language) bool {
+	switch lang {
+	case types.Go:
+		return slices.Contains(returnTypes, "error")
+	case types.Java:
+		return len(throws) > 0
+	}
+	return false
+}
+
+// HasReturnValue checks if the body of a function without declared return types returns a value (eg. javascript),
+// return statements of nested functions are ignored
+func HasReturnValue(body *sitter.Node) bool {
+	if body == nil {
+		return false
+	}
+	// arrow functions with an expression as body
+	if body.Type() != "statement_block" {
+		return true
+	}
+	var visit func(*sitter.Node) bool
+	visit = func(n *sitter.Node) bool {
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			child := n.NamedChild(i)
+			switch child.Type() {
+			case "return_statement":
+				if child.NamedChildCount() > 0 {
+					return true
+				}
+			case "function", "function_expression", "arrow_function", "function_declaration", "method_definition", "class_declaration":
+				continue
+			}
+			if visit(child) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(body)
+}
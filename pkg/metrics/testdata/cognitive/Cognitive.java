@@ -0,0 +1,27 @@
+package org.example;
+
+public class Cognitive {
+  // should have a cognitive complexity of 7
+  int check(int a, boolean b, boolean c) {
+    if (a > 0 && b || c) {
+      return 1;
+    } else if (a < 0) {
+      return 2;
+    } else {
+      try {
+        a = parse(a);
+      } catch (Exception e) {
+        return 3;
+      }
+    }
+    return 0;
+  }
+
+  // should have a cognitive complexity of 3
+  int fib(int n) {
+    if (n < 2) {
+      return n;
+    }
+    return fib(n - 1) + fib(n - 2);
+  }
+}
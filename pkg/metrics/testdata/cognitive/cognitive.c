@@ -0,0 +1,9 @@
+// should have a cognitive complexity of 3
+int loop(int n) {
+  int s = 0;
+  while (n > 0) {
+    s += n > 5 ? 2 : 1;
+    n--;
+  }
+  return s;
+}
@@ -0,0 +1,5 @@
+// should have a cognitive complexity of 3
+function ternary(a) {
+  const f = (x) => x ? 1 : 2;
+  return a ?? f(a);
+}
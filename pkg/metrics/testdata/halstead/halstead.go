@@ -0,0 +1,5 @@
+package examples
+
+func add(a int, b int) int {
+	return a + b
+}
@@ -0,0 +1,4 @@
+function greet(name) {
+  // comments are ignored
+  return "hello \n" + name;
+}
@@ -0,0 +1,22 @@
+package org.example;
+
+public class Lines {
+  int lines(int a) {
+    // a comment
+
+    int b = a * 2;
+    /*
+     * multi line comment
+     */
+    if (b > 10) {
+      b = 10;
+    }
+
+    switch (b) {
+      case 1:
+        b++;
+        break;
+    }
+    return b;
+  }
+}
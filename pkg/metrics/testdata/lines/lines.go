@@ -0,0 +1,20 @@
+package examples
+
+// doc comments are not part of the function
+func lines(a int) int {
+	// a comment
+
+	b := a * 2 // trailing comments are code lines
+	/*
+		multi line comment
+	*/
+	if b > 10 {
+		b = 10
+	}
+
+	switch b {
+	case 1:
+		b++
+	}
+	return b
+}
@@ -0,0 +1,18 @@
+function lines(a) {
+  // a comment
+
+  let b = a * 2;
+  /*
+   * multi line comment
+   */
+  if (b > 10) {
+    b = 10;
+  }
+
+  switch (b) {
+    case 1:
+      b++;
+      break;
+  }
+  return b;
+}
@@ -0,0 +1,15 @@
+function a(x) {
+  return x + 1;
+}
+
+function b(x) {
+  if (x) {
+    return;
+  }
+}
+
+function c(list) {
+  list.forEach(function (x) {
+    return x;
+  });
+}
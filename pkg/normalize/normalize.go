@@ -0,0 +1,144 @@
+// Package normalize maps the raw values of a metric to a comparable scale. Strategies based on the scanned
+// candidates (max, minmax, rank, zscore) change with every scanned subset, the fixed strategy uses absolute
+// thresholds and keeps the scores comparable between runs and repositories.
+package normalize
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+type Strategy string
+
+const (
+	// Max divides by the max value of all candidates
+	Max Strategy = "max"
+	// MinMax scales the values between the min and max value of all candidates
+	MinMax Strategy = "minmax"
+	// Rank is the percentile rank, the share of candidates with a lower value
+	Rank Strategy = "rank"
+	// ZScore is the distance to the mean in standard deviations
+	ZScore Strategy = "zscore"
+	// Fixed divides by an absolute threshold, values above the threshold are capped at 1
+	Fixed Strategy = "fixed"
+)
+
+var Strategies = []Strategy{Max, MinMax, Rank, ZScore, Fixed}
+
+// Thresholds are the default thresholds for the fixed strategy, values reaching the threshold are considered
+// as maximum
+var Thresholds = map[string]float64{
+	"cc":      20,
+	"cog":     30,
+	"loc":     100,
+	"params":  6,
+	"churn":   50,
+	"fanin":   20,
+	"entry":   20,
+	"sink":    20,
+	"pbranch": 10,
+	"tsink":   5,
+}
+
+func ParseStrategy(s string) (Strategy, error) {
+	if s == "" {
+		return Max, nil
+	}
+	strategy := Strategy(strings.ToLower(s))
+	if !slices.Contains(Strategies, strategy) {
+		return "", fmt.Errorf("unknown normalization %s", s)
+	}
+	return strategy, nil
+}
+
+// Normalize normalizes the values of a single metric, the threshold is only used by the fixed strategy.
+// Metrics without any variance (eg. all values are 0) are normalized to 0 by all strategies except fixed.
+func Normalize(strategy Strategy, values []float64, threshold float64) []float64 {
+	normalized := make([]float64, len(values))
+	if len(values) == 0 {
+		return normalized
+	}
+
+	switch strategy {
+	case MinMax:
+		minVal, maxVal := slices.Min(values), slices.Max(values)
+		for i, val := range values {
+			if maxVal > minVal {
+				normalized[i] = (val - minVal) / (maxVal - minVal)
+			}
+		}
+
+	case Rank:
+		sorted := slices.Clone(values)
+		slices.Sort(sorted)
+		for i, val := range values {
+			// all candidates with a lower value, equal values get the same rank
+			lower, _ := slices.BinarySearch(sorted, val)
+			if len(values) > 1 {
+				normalized[i] = float64(lower) / float64(len(values)-1)
+			}
+		}
+
+	case ZScore:
+		mean, stdDev := meanStdDev(values)
+		for i, val := range values {
+			if stdDev > 0 {
+				normalized[i] = (val - mean) / stdDev
+			}
+		}
+
+	case Fixed:
+		for i, val := range values {
+			if threshold > 0 {
+				normalized[i] = math.Min(val/threshold, 1)
+			}
+		}
+
+	default:
+		maxVal := slices.Max(values)
+		for i, val := range values {
+			if maxVal > 0 {
+				normalized[i] = val / maxVal
+			}
+		}
+	}
+	return normalized
+}
+
+// Reference returns the raw value normalized to 1 by the strategy: the max value for max, minmax and rank, the
+// mean plus one standard deviation for zscore and the threshold for fixed. It is 0 if no value is normalized to 1
+// (eg. all values are equal).
+func Reference(strategy Strategy, values []float64, threshold float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	switch strategy {
+	case MinMax, Rank:
+		if maxVal := slices.Max(values); maxVal > slices.Min(values) {
+			return maxVal
+		}
+	case ZScore:
+		if mean, stdDev := meanStdDev(values); stdDev > 0 {
+			return mean + stdDev
+		}
+	case Fixed:
+		return max(threshold, 0)
+	default:
+		return max(slices.Max(values), 0)
+	}
+	return 0
+}
+
+func meanStdDev(values []float64) (mean, stdDev float64) {
+	for _, val := range values {
+		mean += val
+	}
+	mean /= float64(len(values))
+	variance := 0.0
+	for _, val := range values {
+		variance += (val - mean) * (val - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
@@ -0,0 +1,85 @@
+package normalize_test
+
+import (
+	"testing"
+
+	"github.com/jochil/gcs/pkg/normalize"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	values := []float64{2, 4, 4, 10}
+
+	testCases := map[string]struct {
+		strategy normalize.Strategy
+		values   []float64
+		expected []float64
+	}{
+		"max":          {strategy: normalize.Max, values: values, expected: []float64{0.2, 0.4, 0.4, 1}},
+		"minmax":       {strategy: normalize.MinMax, values: values, expected: []float64{0, 0.25, 0.25, 1}},
+		"rank":         {strategy: normalize.Rank, values: values, expected: []float64{0, 1.0 / 3, 1.0 / 3, 1}},
+		"zscore":       {strategy: normalize.ZScore, values: values, expected: []float64{-1, -1.0 / 3, -1.0 / 3, 5.0 / 3}},
+		"fixed":        {strategy: normalize.Fixed, values: values, expected: []float64{0.4, 0.8, 0.8, 1}},
+		"max_zero":     {strategy: normalize.Max, values: []float64{0, 0}, expected: []float64{0, 0}},
+		"minmax_equal": {strategy: normalize.MinMax, values: []float64{3, 3}, expected: []float64{0, 0}},
+		"rank_single":  {strategy: normalize.Rank, values: []float64{3}, expected: []float64{0}},
+		"zscore_equal": {strategy: normalize.ZScore, values: []float64{3, 3}, expected: []float64{0, 0}},
+		"empty":        {strategy: normalize.Max, values: []float64{}, expected: []float64{}},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			normalized := normalize.Normalize(tc.strategy, tc.values, 5)
+			require.Len(t, normalized, len(tc.expected))
+			for i := range tc.expected {
+				assert.InDelta(t, tc.expected[i], normalized[i], 0.0001)
+			}
+		})
+	}
+}
+
+func TestReference(t *testing.T) {
+	values := []float64{2, 4, 4, 10}
+
+	testCases := map[string]struct {
+		strategy normalize.Strategy
+		values   []float64
+		expected float64
+	}{
+		"max":          {strategy: normalize.Max, values: values, expected: 10},
+		"minmax":       {strategy: normalize.MinMax, values: values, expected: 10},
+		"rank":         {strategy: normalize.Rank, values: values, expected: 10},
+		"zscore":       {strategy: normalize.ZScore, values: values, expected: 8},
+		"fixed":        {strategy: normalize.Fixed, values: values, expected: 5},
+		"minmax_equal": {strategy: normalize.MinMax, values: []float64{3, 3}, expected: 0},
+		"zscore_equal": {strategy: normalize.ZScore, values: []float64{3, 3}, expected: 0},
+		"empty":        {strategy: normalize.Max, values: []float64{}, expected: 0},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.InDelta(t, tc.expected, normalize.Reference(tc.strategy, tc.values, 5), 0.0001)
+		})
+	}
+}
+
+func TestNormalize_Stable(t *testing.T) {
+	// the fixed strategy does not depend on the other candidates
+	all := normalize.Normalize(normalize.Fixed, []float64{2, 40}, 20)
+	subset := normalize.Normalize(normalize.Fixed, []float64{2}, 20)
+	assert.Equal(t, all[0], subset[0])
+}
+
+func TestParseStrategy(t *testing.T) {
+	strategy, err := normalize.ParseStrategy("")
+	require.NoError(t, err)
+	assert.Equal(t, normalize.Max, strategy)
+
+	strategy, err = normalize.ParseStrategy("ZScore")
+	require.NoError(t, err)
+	assert.Equal(t, normalize.ZScore, strategy)
+
+	_, err = normalize.ParseStrategy("median")
+	require.Error(t, err)
+}
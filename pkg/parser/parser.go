@@ -2,6 +2,7 @@ package parser
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"slices"
@@ -23,8 +24,14 @@ type Parser struct {
 }
 
 func NewParser(path string, language types.Language) *Parser {
+	return NewParserWith(sitter.NewParser(), path, language)
+}
+
+// NewParserWith creates a parser reusing an existing tree-sitter parser (eg. one per worker),
+// the tree-sitter parser must not be used concurrently
+func NewParserWith(sitterParser *sitter.Parser, path string, language types.Language) *Parser {
 	parser := &Parser{
-		Parser:   sitter.NewParser(),
+		Parser:   sitterParser,
 		path:     path,
 		language: language,
 	}
@@ -33,24 +40,42 @@ func NewParser(path string, language types.Language) *Parser {
 	return parser
 }
 
+// ParseFile returns the candidates of a source code file, the language is guessed by the file extension
+func ParseFile(path string) (candidate.Candidates, error) {
+	language, err := helper.GuessLanguage(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewParser(path, language).Parse()
+}
+
 // Parse returns a list of candidates for a given source code file
-func (p *Parser) Parse() candidate.Candidates {
+func (p *Parser) Parse() (candidate.Candidates, error) {
+	return p.ParseContext(context.Background())
+}
+
+// ParseContext is like Parse but cancelling the context interrupts the parsing and its deadline limits the parsing
+// time (eg. for pathological inputs)
+func (p *Parser) ParseContext(ctx context.Context) (candidate.Candidates, error) {
 	slog.Info("Start parsing", "file", p.path)
+	if err := helper.ContextErr(ctx); err != nil {
+		return nil, err
+	}
 
 	var err error
 	p.sourceCode, err = os.ReadFile(p.path)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	tree, err := p.ParseCtx(context.Background(), nil, p.sourceCode)
+	tree, err := helper.Parse(ctx, p.Parser, p.sourceCode)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("unable to parse %s: %w", p.path, err)
 	}
 
 	root := tree.RootNode()
 	packageName := p.findPackage(root)
-	return p.findFunctions(root, packageName, nil)
+	return p.findFunctions(root, packageName, nil), nil
 }
 
 func (p *Parser) findFunctions(node *sitter.Node, packageName string, class *candidate.Class) candidate.Candidates {
@@ -74,6 +99,10 @@ func (p *Parser) findFunctions(node *sitter.Node, packageName string, class *can
 
 		case "function_definition":
 			declarator := child.ChildByFieldName("declarator")
+			// functions returning a pointer are wrapped in a pointer_declarator
+			for declarator != nil && declarator.Type() == "pointer_declarator" {
+				declarator = declarator.ChildByFieldName("declarator")
+			}
 			c.Function.Name = p.name(declarator)
 			p.parseFunction(child, c)
 
@@ -112,7 +141,10 @@ func (p *Parser) findFunctions(node *sitter.Node, packageName string, class *can
 		if c.Function.Name != "" {
 
 			c.AST = child
+			c.StartLine = int(child.StartPoint().Row) + 1
+			c.EndLine = int(child.EndPoint().Row) + 1
 			c.Code = child.Content(p.sourceCode)
+			c.Source = p.sourceCode
 
 			slog.Info("Found candidate", "function", c)
 			candidates = append(candidates, c)
@@ -130,9 +162,10 @@ func (p *Parser) parseFunction(node *sitter.Node, c *candidate.Candidate) {
 }
 
 func (p *Parser) parseReceiver(node *sitter.Node, c *candidate.Candidate) {
-	if receiver := node.ChildByFieldName("receiver"); receiver != nil {
+	if receiver := node.ChildByFieldName("receiver"); receiver != nil && receiver.NamedChildCount() > 0 {
+		// using the plain type name (eg. MyStruct for *MyStruct)
 		c.Class = &candidate.Class{
-			Name: p.parseParameters(receiver)[0].Type,
+			Name: p.name(receiver.NamedChild(0).ChildByFieldName("type")),
 		}
 	}
 }
@@ -150,8 +183,60 @@ func (p *Parser) parseSignature(node *sitter.Node, f *candidate.Function) {
 		returnFieldName = "result"
 	}
 
+	if p.language == types.C {
+		p.parseCSignature(node, f)
+		return
+	}
+
 	f.Parameters = p.parseParameters(node.ChildByFieldName("parameters"))
 	f.ReturnValues = p.parseParameters(node.ChildByFieldName(returnFieldName))
+	if p.language == types.Java {
+		f.Throws = p.parseThrows(node)
+	}
+}
+
+// c: the parameters are part of the (function) declarator and the return type is the type of the definition
+func (p *Parser) parseCSignature(node *sitter.Node, f *candidate.Function) {
+	f.Parameters = []*candidate.Parameter{}
+	for declarator := node.ChildByFieldName("declarator"); declarator != nil; declarator = declarator.ChildByFieldName("declarator") {
+		if declarator.Type() == "function_declarator" {
+			f.Parameters = p.parseParameters(declarator.ChildByFieldName("parameters"))
+			break
+		}
+	}
+
+	f.ReturnValues = []*candidate.Parameter{}
+	if returnType := node.ChildByFieldName("type"); returnType != nil && returnType.Content(p.sourceCode) != "void" {
+		typeName := returnType.Content(p.sourceCode) + p.cTypeSuffix(node.ChildByFieldName("declarator"))
+		f.ReturnValues = append(f.ReturnValues, &candidate.Parameter{Name: types.NoName, Type: typeName})
+	}
+}
+
+// returns the pointer/array part of a c type (eg. "*" for char *p)
+func (p *Parser) cTypeSuffix(declarator *sitter.Node) string {
+	suffix := ""
+	for ; declarator != nil; declarator = declarator.ChildByFieldName("declarator") {
+		switch declarator.Type() {
+		case "pointer_declarator", "abstract_pointer_declarator":
+			suffix += "*"
+		case "array_declarator", "abstract_array_declarator":
+			suffix += "[]"
+		}
+	}
+	return suffix
+}
+
+// java: returns the exceptions declared by throws
+func (p *Parser) parseThrows(node *sitter.Node) []string {
+	throws := helper.FirstChildByType(node, "throws")
+	if throws == nil {
+		return nil
+	}
+	exceptions := []string{}
+	for i := 0; i < int(throws.NamedChildCount()); i++ {
+		exceptions = append(exceptions, throws.NamedChild(i).Content(p.sourceCode))
+	}
+	return exceptions
 }
 
 func (p *Parser) parseParameters(node *sitter.Node) []*candidate.Parameter {
@@ -164,6 +249,10 @@ func (p *Parser) parseParameters(node *sitter.Node) []*candidate.Parameter {
 	case "parameter_list", "formal_parameters":
 		for i := 0; i < int(node.NamedChildCount()); i++ {
 			child := node.NamedChild(i)
+			// c: f(void) has no parameters
+			if p.language == types.C && child.Content(p.sourceCode) == "void" {
+				continue
+			}
 			params = append(params, p.parseParameter(child))
 		}
 
@@ -191,9 +280,19 @@ func (p *Parser) parseParameter(param *sitter.Node) *candidate.Parameter {
 		typeName = p.typeName(param.ChildByFieldName("type"))
 
 	case "parameter_declaration", "formal_parameter":
+		if p.language == types.C {
+			declarator := param.ChildByFieldName("declarator")
+			name = p.name(declarator)
+			typeName = param.ChildByFieldName("type").Content(p.sourceCode) + p.cTypeSuffix(declarator)
+			break
+		}
 		name = p.name(param.ChildByFieldName("name"))
 		typeName = p.typeName(param.ChildByFieldName("type"))
 
+	case "variadic_parameter_declaration":
+		name = p.name(param.ChildByFieldName("name"))
+		typeName = "..." + p.typeName(param.ChildByFieldName("type"))
+
 	default:
 		name = types.NoName
 		typeName = p.typeName(param)
@@ -274,7 +373,15 @@ func (p *Parser) typeName(node *sitter.Node) string {
 		"array_type",
 		"generic_type",
 		"predefined_type",
-		"union_type":
+		"union_type",
+		// go
+		"slice_type",
+		"pointer_type",
+		"qualified_type",
+		"map_type",
+		"channel_type",
+		"function_type",
+		"interface_type":
 		return node.Content(p.sourceCode)
 
 	case "spread_parameter":
@@ -13,7 +13,7 @@ func TestC(t *testing.T) {
 		{
 			name:         "main",
 			params:       []*candidate.Parameter{},
-			returnValues: []*candidate.Parameter{},
+			returnValues: simpleReturn(t, "int"),
 			visibility:   types.VisibilityPublic,
 		},
 		{
@@ -22,6 +22,22 @@ func TestC(t *testing.T) {
 			returnValues: []*candidate.Parameter{},
 			visibility:   types.VisibilityPublic,
 		},
+		{
+			name: "b",
+			params: []*candidate.Parameter{
+				{Name: "input", Type: "char*"},
+				{Name: "buf", Type: "unsigned char[]"},
+				{Name: "len", Type: "size_t"},
+			},
+			returnValues: simpleReturn(t, "char*"),
+			visibility:   types.VisibilityPublic,
+		},
+		{
+			name:         "c",
+			params:       []*candidate.Parameter{},
+			returnValues: simpleReturn(t, "int"),
+			visibility:   types.VisibilityPublic,
+		},
 	}
 	runParserTests(t, tests, "testdata/c/function.c", types.C)
 }
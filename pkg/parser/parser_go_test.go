@@ -50,6 +50,22 @@ func TestGo_SimpleFunction(t *testing.T) {
 			returnValues: []*candidate.Parameter{},
 			visibility:   types.VisibilityPrivate,
 		},
+		{
+			name:        "F",
+			packageName: "examples",
+			params: []*candidate.Parameter{
+				{Name: "data", Type: "[]byte"},
+				{Name: "r", Type: "io.Reader"},
+				{Name: "b", Type: "*bytes.Buffer"},
+				{Name: "m", Type: "map[string]int"},
+				{Name: "opts", Type: "...string"},
+			},
+			returnValues: []*candidate.Parameter{
+				{Name: types.NoName, Type: "[]byte"},
+				{Name: types.NoName, Type: "error"},
+			},
+			visibility: types.VisibilityPublic,
+		},
 	}
 
 	runParserTests(t, tests, "testdata/golang/function.go", types.Go)
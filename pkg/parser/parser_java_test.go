@@ -181,13 +181,25 @@ func TestJava_Parameter(t *testing.T) {
 			returnValues: []*candidate.Parameter{},
 			visibility:   types.VisibilityPublic,
 		},
+		{
+			name:        "Throws",
+			packageName: "org.example",
+			class:       "Foo",
+			params: []*candidate.Parameter{
+				{Name: "a", Type: "byte[]"},
+			},
+			returnValues: []*candidate.Parameter{},
+			visibility:   types.VisibilityPublic,
+			throws:       []string{"IOException", "ParseException"},
+		},
 	}
 
 	runParserTests(t, tests, "testdata/java/parameter.java", types.Java)
 }
 
 func TestJava_Constructor(t *testing.T) {
-	candidates := parser.NewParser("testdata/java/constructor.java", types.Java).Parse()
+	candidates, err := parser.NewParser("testdata/java/constructor.java", types.Java).Parse()
+	require.NoError(t, err)
 	require.Len(t, candidates, 1)
 	require.Len(t, candidates[0].Class.Constructors, 2)
 }
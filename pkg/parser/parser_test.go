@@ -1,12 +1,22 @@
 package parser_test
 
 import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jochil/gcs/pkg/candidate"
+	"github.com/jochil/gcs/pkg/helper"
 	"github.com/jochil/gcs/pkg/parser"
 	"github.com/jochil/gcs/pkg/types"
+	sitter "github.com/smacker/go-tree-sitter"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func simpleReturn(t *testing.T, typeName string) []*candidate.Parameter {
@@ -24,10 +34,12 @@ type candidateTestCase struct {
 	class        string
 	packageName  string
 	static       bool
+	throws       []string
 }
 
 func runParserTests(t *testing.T, tests []candidateTestCase, path string, language types.Language) {
-	candidates := parser.NewParser(path, language).Parse()
+	candidates, err := parser.NewParser(path, language).Parse()
+	require.NoError(t, err)
 	for i, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			assertCandidate(t, tc, candidates[i])
@@ -37,7 +49,7 @@ func runParserTests(t *testing.T, tests []candidateTestCase, path string, langua
 
 func assertParams(t *testing.T, expected []*candidate.Parameter, actual []*candidate.Parameter) {
 	t.Helper()
-	assert.Len(t, actual, len(expected), "invalid parameter amount")
+	require.Len(t, actual, len(expected), "invalid parameter amount")
 	for i, p := range actual {
 		assert.Equal(t, expected[i].Name, p.Name, "invalid parameter name")
 		assert.Equal(t, expected[i].Type, p.Type, "invalid parameter type")
@@ -57,4 +69,50 @@ func assertCandidate(t *testing.T, tc candidateTestCase, c *candidate.Candidate)
 
 	assertParams(t, tc.params, c.Function.Parameters)
 	assertParams(t, tc.returnValues, c.Function.ReturnValues)
+	assert.Equal(t, tc.throws, c.Function.Throws, "invalid throws")
+}
+
+func TestParseFile_Errors(t *testing.T) {
+	_, err := parser.ParseFile("testdata/unsupported.txt")
+	require.ErrorIs(t, err, helper.ErrUnsupportedLanguage)
+
+	_, err = parser.ParseFile("testdata/missing.go")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	_, err = parser.NewParser("testdata/missing.java", types.Java).Parse()
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestParseContext_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := parser.NewParser("testdata/golang/function.go", types.Go).ParseContext(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestParseContext_CanceledWhileParsing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "large.go")
+	source := strings.Builder{}
+	source.WriteString("package large\n")
+	for i := 0; i < 200000; i++ {
+		fmt.Fprintf(&source, "func f%d(a int) int {\n\tif a > %d {\n\t\treturn a\n\t}\n\treturn 0\n}\n", i, i)
+	}
+	require.NoError(t, os.WriteFile(path, []byte(source.String()), 0o644))
+
+	sitterParser := sitter.NewParser()
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := parser.NewParserWith(sitterParser, path, types.Go).ParseContext(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// the parser can be reused, also with contexts canceled right after the parse
+	for i := 0; i < 200; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		candidates, err := parser.NewParserWith(sitterParser, "testdata/golang/function.go", types.Go).ParseContext(ctx)
+		cancel()
+		require.NoError(t, err)
+		assert.NotEmpty(t, candidates)
+	}
 }
@@ -5,3 +5,11 @@ int main() {
 
 void a() {
 }
+
+char *b(const char *input, unsigned char buf[], size_t len) {
+   return 0;
+}
+
+int c(void) {
+   return 0;
+}
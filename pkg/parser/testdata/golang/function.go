@@ -14,3 +14,7 @@ func D(d string) {
 }
 
 func e() {}
+
+func F(data []byte, r io.Reader, b *bytes.Buffer, m map[string]int, opts ...string) ([]byte, error) {
+	return nil, nil
+}
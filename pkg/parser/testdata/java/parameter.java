@@ -8,4 +8,7 @@ public class Foo {
 
   void Generic(Map<String, Integer> a) {
   }
+
+  void Throws(byte[] a) throws IOException, ParseException {
+  }
 }
@@ -0,0 +1,114 @@
+package search
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jochil/gcs/pkg/candidate"
+	"github.com/jochil/gcs/pkg/types"
+)
+
+var visibilities = []string{types.VisibilityPublic, types.VisibilityPrivate, types.VisibilityProtected}
+
+// compiles the filter options once, the returned function accepts the candidates matching all filters
+func (opts Options) filter(srcPaths []string) (func(c *candidate.Candidate) bool, error) {
+	filters := []func(c *candidate.Candidate) bool{}
+	if opts.Filter != nil {
+		filters = append(filters, opts.Filter)
+	}
+
+	if opts.FilterExpression != "" {
+		e, err := candidate.ParseFilter(opts.FilterExpression)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, func(c *candidate.Candidate) bool {
+			ok, err := e.Match(c.FilterVariables())
+			if err != nil {
+				slog.Warn("unable to evaluate filter", "filter", e, "candidate", c, "error", err)
+			}
+			return ok
+		})
+	}
+
+	if opts.MinScore != nil {
+		minScore := *opts.MinScore
+		filters = append(filters, func(c *candidate.Candidate) bool {
+			return c.Score >= minScore
+		})
+	}
+
+	if len(opts.Visibility) > 0 {
+		for _, v := range opts.Visibility {
+			if !slices.Contains(visibilities, strings.ToLower(v)) {
+				return nil, fmt.Errorf("unknown visibility %s, available: %s", v, strings.Join(visibilities, ", "))
+			}
+		}
+		filters = append(filters, func(c *candidate.Candidate) bool {
+			return slices.ContainsFunc(opts.Visibility, func(v string) bool {
+				return strings.EqualFold(v, c.Function.Visibility)
+			})
+		})
+	}
+
+	if opts.ExcludeFunc != "" {
+		re, err := regexp.Compile(opts.ExcludeFunc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid function pattern: %w", err)
+		}
+		filters = append(filters, func(c *candidate.Candidate) bool {
+			return !re.MatchString(c.Function.Name)
+		})
+	}
+
+	if len(opts.IncludePaths) > 0 {
+		for _, glob := range opts.IncludePaths {
+			if _, err := path.Match(glob, ""); err != nil {
+				return nil, fmt.Errorf("invalid path pattern %s: %w", glob, err)
+			}
+		}
+		filters = append(filters, func(c *candidate.Candidate) bool {
+			return matchPath(opts.IncludePaths, srcPaths, c.Path)
+		})
+	}
+
+	return func(c *candidate.Candidate) bool {
+		for _, f := range filters {
+			if !f(c) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// checks if the path relative to the scanned directory matches one of the globs, a glob matching a
+// directory includes all files below
+func matchPath(globs []string, srcPaths []string, file string) bool {
+	rel := filepath.ToSlash(file)
+	for _, srcPath := range srcPaths {
+		if r, err := filepath.Rel(srcPath, file); err == nil && r != "." && !strings.HasPrefix(r, "..") {
+			rel = filepath.ToSlash(r)
+			break
+		}
+	}
+
+	for _, glob := range globs {
+		glob = strings.TrimSuffix(filepath.ToSlash(glob), "/")
+		// the file itself and all parent directories
+		for p := rel; p != "." && p != "/"; p = path.Dir(p) {
+			if ok, _ := path.Match(glob, p); ok {
+				return true
+			}
+		}
+		if ok, _ := path.Match(glob, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
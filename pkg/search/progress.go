@@ -0,0 +1,64 @@
+package search
+
+import "sync"
+
+// Phase is a step of the search
+type Phase string
+
+const (
+	// PhaseWalk collects the source and test files
+	PhaseWalk Phase = "walk"
+	// PhaseParse parses the files and calculates the metrics of every file
+	PhaseParse Phase = "parse"
+	// PhaseScore calculates the metrics depending on all candidates (eg. the call graph) and the scores
+	PhaseScore Phase = "score"
+	// PhaseDone is reported once the search is finished
+	PhaseDone Phase = "done"
+)
+
+// Progress is the state of a running search
+type Progress struct {
+	Phase Phase
+	// Discovered is the number of source and test files found so far
+	Discovered int
+	// Parsed is the number of parsed (or skipped) files
+	Parsed int
+	// Candidates is the number of candidates found so far
+	Candidates int
+}
+
+// reports the progress to the callback of the options, the callback is never called concurrently and never
+// while holding the lock. Updates arriving while the callback runs are merged, the running caller delivers the
+// latest progress afterwards, so a slow callback blocks at most one worker.
+type progressReporter struct {
+	mu         sync.Mutex
+	callback   func(Progress)
+	progress   Progress
+	version    int
+	delivering bool
+}
+
+func (r *progressReporter) update(change func(p *Progress)) {
+	if r.callback == nil {
+		return
+	}
+	r.mu.Lock()
+	change(&r.progress)
+	r.version++
+	if r.delivering {
+		r.mu.Unlock()
+		return
+	}
+	r.delivering = true
+	for {
+		progress, version := r.progress, r.version
+		r.mu.Unlock()
+		r.callback(progress)
+		r.mu.Lock()
+		if r.version == version {
+			r.delivering = false
+			r.mu.Unlock()
+			return
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package search
+
+import (
+	"fmt"
+
+	"github.com/jochil/gcs/pkg/callgraph"
+	"github.com/jochil/gcs/pkg/candidate"
+	"github.com/jochil/gcs/pkg/config"
+)
+
+// Result contains the candidates of a search and the files skipped because of errors
+type Result struct {
+	Candidates candidate.Candidates
+	Skipped    []*FileError
+	// Graph is the call graph of all candidates, including the ones removed by filters or the limit
+	Graph callgraph.Graph
+	// ConfigFile is the path of the loaded config file, empty if there is none
+	ConfigFile string
+	// Scoring is the resolved scoring of the selected profile, including the normalization set by the options
+	Scoring *config.Scoring
+}
+
+// FileError is an error reading or parsing a single file
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
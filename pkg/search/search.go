@@ -1,60 +1,398 @@
 package search
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io/fs"
+	"log/slog"
 	"path/filepath"
+	"slices"
 	"sort"
+	"time"
 
+	"github.com/jochil/gcs/pkg/callgraph"
 	"github.com/jochil/gcs/pkg/candidate"
+	"github.com/jochil/gcs/pkg/churn"
+	"github.com/jochil/gcs/pkg/config"
+	"github.com/jochil/gcs/pkg/coverage"
 	"github.com/jochil/gcs/pkg/filter"
 	"github.com/jochil/gcs/pkg/helper"
 	"github.com/jochil/gcs/pkg/parser"
+	"github.com/jochil/gcs/pkg/sinks"
+	"github.com/jochil/gcs/pkg/testcase"
+	"github.com/jochil/gcs/pkg/types"
+	sitter "github.com/smacker/go-tree-sitter"
 )
 
 type Options struct {
-	Filter     func(c *candidate.Candidate) bool
-	Extensions []string
-	Limit      int
+	Filter func(c *candidate.Candidate) bool
+	// FilterExpression is an expression over the candidate fields and metrics
+	// (eg. `language == "Go" && metrics.cc >= 5 && !has_fuzz_test`)
+	FilterExpression string
+	// MinScore removes candidates with a lower score
+	MinScore *float64
+	// Visibility keeps only functions with one of the visibilities (public, private, protected)
+	Visibility []string
+	// ExcludeFunc is a regular expression removing functions by their name
+	ExcludeFunc string
+	// IncludePaths are globs relative to the scanned directories, a matching directory includes all files below
+	IncludePaths []string
+	Extensions   []string
+	Limit        int
+	// Churn adds metrics based on the git history of the scanned files
+	Churn bool
+	// CoverageFiles are existing coverage reports (go coverprofile, LCOV, JaCoCo or Cobertura)
+	CoverageFiles []string
+	// HideFuzzed removes candidates already called by a fuzz target
+	HideFuzzed bool
+	// SinksFile is a yaml file extending the default catalog of sinks
+	SinksFile string
+	// ConfigFile contains the scoring profiles and sinks, if not set a .gcs.yaml in the scanned
+	// directories or the working directory is used
+	ConfigFile string
+	// Profile selects the scoring profile (eg. fuzz, unit)
+	Profile string
+	// Normalization overrides the normalization strategy of the config (max, minmax, rank, zscore, fixed)
+	Normalization string
+	// Jobs is the number of workers parsing the files and calculating the metrics, GOMAXPROCS if < 1
+	Jobs int
+	// FileTimeout limits the time for parsing a single file, files exceeding it are skipped
+	FileTimeout time.Duration
+	// Progress is called after every step (eg. a discovered or parsed file), it is never called concurrently.
+	// Steps finished while the callback runs are merged into the next call.
+	Progress func(p Progress)
 }
 
 func Search(srcPaths []string) (candidate.Candidates, error) {
 	return SearchWithOptions(srcPaths, Options{})
 }
 
+// SearchWithOptions returns the scored candidates, files which could not be parsed are skipped
+// (see Scan for the list of skipped files)
 func SearchWithOptions(srcPaths []string, opts Options) (candidate.Candidates, error) {
-	// walk over the given path and all child directories, parse the supported source code files
-	// and collect possible candidates
+	return SearchContext(context.Background(), srcPaths, opts)
+}
+
+// SearchContext is like SearchWithOptions but stops walking and parsing the files if the context is done
+func SearchContext(ctx context.Context, srcPaths []string, opts Options) (candidate.Candidates, error) {
+	result, err := ScanContext(ctx, srcPaths, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, skipped := range result.Skipped {
+		slog.Warn("skipped file", "path", skipped.Path, "error", skipped.Err)
+	}
+	return result.Candidates, nil
+}
+
+// Scan returns the scored candidates together with the files skipped because of errors. Only errors
+// affecting the whole search (eg. an invalid config or a missing source path) are returned.
+func Scan(srcPaths []string, opts Options) (*Result, error) {
+	return ScanContext(context.Background(), srcPaths, opts)
+}
+
+// ScanContext is like Scan but stops walking, parsing and scoring if the context is done, the error of the
+// context is returned in this case
+func ScanContext(ctx context.Context, srcPaths []string, opts Options) (*Result, error) {
+	s, err := newScanner(srcPaths, opts)
+	if err != nil {
+		return nil, err
+	}
+	candidates, graph, skipped, err := s.parse(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.score(ctx, candidates, graph, skipped)
+}
+
+// shared by Scan and Stream, holds everything loaded before the files are parsed
+type scanner struct {
+	srcPaths   []string
+	opts       Options
+	configFile string
+	accept     func(c *candidate.Candidate) bool
+	scoreOpts  candidate.ScoreOptions
+	progress   *progressReporter
+}
+
+func newScanner(srcPaths []string, opts Options) (*scanner, error) {
+	// the filters are applied after the scoring, so the scores do not depend on them
+	accept, err := opts.filter(srcPaths)
+	if err != nil {
+		return nil, err
+	}
+	// the config and reports are loaded before parsing, so invalid ones fail fast
+	cfg, configFile, err := loadConfig(srcPaths, opts.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	scoring, err := cfg.Scoring(opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Normalization != "" {
+		if err := scoring.SetNormalization(opts.Normalization); err != nil {
+			return nil, err
+		}
+	}
+
+	scoreOpts := candidate.ScoreOptions{Scoring: scoring, Sinks: cfg.Catalog(), Jobs: opts.Jobs}
+	if opts.Churn {
+		scoreOpts.Churn = churn.NewAnalyzer()
+	}
+	if opts.SinksFile != "" {
+		categories, err := sinks.ReadFile(opts.SinksFile)
+		if err != nil {
+			return nil, err
+		}
+		scoreOpts.Sinks = scoreOpts.Sinks.Merge(categories...)
+	}
+	if len(opts.CoverageFiles) > 0 {
+		profile, err := coverage.Load(opts.CoverageFiles...)
+		if err != nil {
+			return nil, err
+		}
+		scoreOpts.Coverage = profile
+	}
+
+	return &scanner{
+		srcPaths:   srcPaths,
+		opts:       opts,
+		configFile: configFile,
+		accept:     accept,
+		scoreOpts:  scoreOpts,
+		progress:   &progressReporter{callback: opts.Progress},
+	}, nil
+}
+
+// parses all files and calculates the metrics of every file right after parsing it. The test files are parsed
+// first, so the candidates are already linked to their tests. If emit is set it gets the candidates of every
+// parsed file, their ASTs are released before. The candidates are returned in the order of the files.
+func (s *scanner) parse(ctx context.Context, emit func(candidates candidate.Candidates)) (candidate.Candidates, *callgraph.Builder, []*FileError, error) {
+	files, skipped, err := collectFiles(ctx, s.srcPaths, s.opts.Extensions, s.progress)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	s.progress.update(func(p *Progress) { p.Phase = PhaseParse })
+
+	// the indexes of the test and source files in files
+	testFiles := []int{}
+	sourceFiles := []int{}
+	for i, file := range files {
+		if file.test {
+			testFiles = append(testFiles, i)
+		} else {
+			sourceFiles = append(sourceFiles, i)
+		}
+	}
+	errs := make([]error, len(files))
+
+	parsedTests := make([][]*testcase.Test, len(files))
+	s.parseFiles(ctx, files, testFiles, errs, func(ctx context.Context, sitterParser *sitter.Parser, i int, language types.Language) (int, error) {
+		var err error
+		parsedTests[i], err = testcase.ParseWith(ctx, sitterParser, files[i].path, language)
+		return 0, err
+	})
+	scoreOpts := s.scoreOpts
+	for _, tests := range parsedTests {
+		scoreOpts.Tests = append(scoreOpts.Tests, tests...)
+	}
+
+	calc := candidate.NewCalculator(scoreOpts)
+	graph := callgraph.NewBuilder()
+	parsedCandidates := make([]candidate.Candidates, len(files))
+	s.parseFiles(ctx, files, sourceFiles, errs, func(ctx context.Context, sitterParser *sitter.Parser, i int, language types.Language) (int, error) {
+		candidates, err := parser.NewParserWith(sitterParser, files[i].path, language).ParseContext(ctx)
+		if err != nil {
+			return 0, err
+		}
+		calc.Calculate(candidates)
+		for _, c := range candidates {
+			graph.Add(c)
+		}
+		if emit != nil {
+			for _, c := range candidates {
+				c.Release()
+			}
+			emit(candidates)
+		}
+		parsedCandidates[i] = candidates
+		return len(candidates), nil
+	})
+	if err := helper.ContextErr(ctx); err != nil {
+		return nil, nil, nil, err
+	}
+
 	candidates := candidate.Candidates{}
+	for i, file := range files {
+		if errs[i] != nil {
+			skipped = append(skipped, &FileError{Path: file.path, Err: errs[i]})
+			continue
+		}
+		candidates = append(candidates, parsedCandidates[i]...)
+	}
+	return candidates, graph, skipped, nil
+}
+
+// calculates the metrics depending on all candidates and the scores, filters, sorts and limits the candidates
+func (s *scanner) score(ctx context.Context, candidates candidate.Candidates, graph *callgraph.Builder, skipped []*FileError) (*Result, error) {
+	s.progress.update(func(p *Progress) { p.Phase = PhaseScore })
+
+	// metrics based on the relations between the candidates
+	g, err := graph.Build(candidates)
+	if err != nil {
+		return nil, err
+	}
+	if err := callgraph.ApplyContext(ctx, g); err != nil {
+		return nil, err
+	}
+	if err := helper.ContextErr(ctx); err != nil {
+		return nil, err
+	}
+	candidates.CalculateCustomMetrics()
+	if err := helper.ContextErr(ctx); err != nil {
+		return nil, err
+	}
+
+	candidates.Score(s.scoreOpts)
+	candidates = candidates.Filter(s.accept)
+	if s.opts.HideFuzzed {
+		candidates = candidates.Filter(func(c *candidate.Candidate) bool {
+			return !c.Metrics.HasFuzzTest
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	if limit := s.opts.Limit; limit > 0 && limit < len(candidates) {
+		candidates = candidates[:limit]
+	}
+
+	s.progress.update(func(p *Progress) { p.Phase = PhaseDone })
+	return &Result{Candidates: candidates, Skipped: skipped, Graph: g, ConfigFile: s.configFile, Scoring: s.scoreOpts.Scoring}, nil
+}
+
+// a source file containing candidates or a test file
+type sourceFile struct {
+	path string
+	test bool
+}
+
+// walks over the given paths and all child directories and collects the supported source and test files,
+// unreadable directories and files below the given paths are skipped
+func collectFiles(ctx context.Context, srcPaths []string, extensions []string, progress *progressReporter) ([]sourceFile, []*FileError, error) {
+	progress.update(func(p *Progress) { p.Phase = PhaseWalk })
+	files := []sourceFile{}
+	skipped := []*FileError{}
 	for _, srcPath := range srcPaths {
 		err := filepath.WalkDir(srcPath, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
+			if ctxErr := helper.ContextErr(ctx); ctxErr != nil {
+				return ctxErr
 			}
-			if !d.IsDir() {
-				if filter.Valid(path, opts.Extensions) {
-					nc := parser.NewParser(helper.GuessLanguage(path)).Parse()
-					candidates = append(candidates, nc...)
+			if err != nil {
+				if path == srcPath {
+					return err
 				}
+				skipped = append(skipped, &FileError{Path: path, Err: err})
+				if d != nil && d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if rel := scanRelative(srcPath, path); filter.Valid(rel, extensions) {
+				files = append(files, sourceFile{path: path})
+			} else if filter.Supported(rel, extensions) && filter.IsTest(rel) {
+				files = append(files, sourceFile{path: path, test: true})
+			} else {
+				return nil
 			}
+			progress.update(func(p *Progress) { p.Discovered++ })
 			return nil
 		})
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
-	candidates.CalcScore()
-	candidates = candidates.Filter(opts.Filter)
+	return files, skipped, nil
+}
 
-	sort.Slice(candidates, func(i, j int) bool {
-		return candidates[i].Score > candidates[j].Score
-	})
+// returns the path of a file relative to the parent of the scanned path, so the directories above (eg. a checkout
+// below a src/test directory) are not used for detecting test files
+func scanRelative(srcPath, path string) string {
+	absSrc, err := filepath.Abs(srcPath)
+	if err != nil {
+		return path
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(filepath.Dir(absSrc), absPath)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// parses the files with the given indexes on a worker pool, every worker reuses its tree-sitter parser. The
+// parse function gets the index and the language of a file and returns the number of found candidates, the
+// errors are stored at the index of the file. Once the context is done the remaining files are not parsed
+// anymore.
+func (s *scanner) parseFiles(ctx context.Context, files []sourceFile, indexes []int, errs []error, parse func(ctx context.Context, sitterParser *sitter.Parser, i int, language types.Language) (int, error)) {
+	helper.Parallel(len(indexes), s.opts.Jobs, func() func(j int) {
+		sitterParser := sitter.NewParser()
+		return func(j int) {
+			i := indexes[j]
+			if errs[i] = helper.ContextErr(ctx); errs[i] != nil {
+				return
+			}
+			found := 0
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf("panic while parsing: %v", r)
+					// the tree-sitter parser might be in an inconsistent state
+					sitterParser = sitter.NewParser()
+				}
+				s.progress.update(func(p *Progress) {
+					p.Parsed++
+					p.Candidates += found
+				})
+			}()
+
+			language, err := helper.GuessLanguage(files[i].path)
+			if err != nil {
+				errs[i] = err
+				return
+			}
 
-	if opts.Limit > 0 {
-		if opts.Limit > len(candidates) {
-			opts.Limit = len(candidates)
+			fileCtx := ctx
+			if s.opts.FileTimeout > 0 {
+				var cancel context.CancelFunc
+				fileCtx, cancel = context.WithTimeout(ctx, s.opts.FileTimeout)
+				defer cancel()
+			}
+			found, errs[i] = parse(fileCtx, sitterParser, i, language)
+			if errors.Is(errs[i], context.DeadlineExceeded) && helper.ContextErr(ctx) == nil {
+				errs[i] = fmt.Errorf("parsing exceeded the timeout of %s: %w", s.opts.FileTimeout, errs[i])
+			}
 		}
-		candidates = candidates[:opts.Limit]
-	}
+	})
+}
 
-	return candidates, nil
+// loads the given config file or the first .gcs.yaml found in the scanned directories or the working directory
+func loadConfig(srcPaths []string, path string) (*config.Config, string, error) {
+	if path == "" {
+		path = config.Find(append(slices.Clone(srcPaths), ".")...)
+	}
+	if path == "" {
+		return &config.Config{}, "", nil
+	}
+	cfg, err := config.Load(path)
+	return cfg, path, err
 }
@@ -1,9 +1,16 @@
 package search_test
 
 import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/jochil/gcs/pkg/candidate"
+	"github.com/jochil/gcs/pkg/normalize"
 	"github.com/jochil/gcs/pkg/search"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -54,3 +61,340 @@ func TestSearchOptions_Filter(t *testing.T) {
 	assert.Len(t, candidates, 1, "wrong number of candidates")
 	assert.Equal(t, "A", candidates[0].Function.Name)
 }
+
+func TestSearchOptions_Coverage(t *testing.T) {
+	report := filepath.Join(t.TempDir(), "lcov.info")
+	require.NoError(t, os.WriteFile(report, []byte("SF:testdata/test.js\nDA:1,1\nDA:2,0\nend_of_record\n"), 0o644))
+
+	candidates, err := search.SearchWithOptions([]string{"testdata"}, search.Options{
+		CoverageFiles: []string{report},
+	})
+	require.NoError(t, err)
+	require.Len(t, candidates, 6, "wrong number of candidates")
+	for _, c := range candidates {
+		if filepath.Ext(c.Path) == ".js" {
+			assert.True(t, c.Metrics.HasCoverage)
+			assert.Equal(t, 0.5, c.Metrics.LineCoverage)
+		} else {
+			assert.False(t, c.Metrics.HasCoverage)
+		}
+	}
+}
+
+func TestSearchOptions_HideFuzzed(t *testing.T) {
+	candidates, err := search.Search([]string{"testdata"})
+	require.NoError(t, err)
+	for _, c := range candidates {
+		assert.Equal(t, c.Function.Name == "a", c.Metrics.HasFuzzTest, "wrong fuzz test for %s", c.Function.Name)
+	}
+
+	candidates, err = search.SearchWithOptions([]string{"testdata"}, search.Options{
+		HideFuzzed: true,
+	})
+	require.NoError(t, err)
+	assert.Len(t, candidates, 5, "wrong number of candidates")
+}
+
+func TestSearchOptions_Config(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("profiles:\n  lines:\n    score: \"loc * 10\"\n"), 0o644))
+
+	candidates, err := search.SearchWithOptions([]string{"../callgraph/testdata/golang"}, search.Options{
+		ConfigFile: path,
+		Profile:    "lines",
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, candidates)
+	// the longest function gets the maximum score
+	assert.Equal(t, 10.0, candidates[0].Score)
+	for _, c := range candidates {
+		assert.LessOrEqual(t, c.Metrics.LogicalLinesOfCode, candidates[0].Metrics.LogicalLinesOfCode)
+	}
+
+	// the result contains the config and the resolved scoring
+	result, err := search.Scan([]string{"../callgraph/testdata/golang"}, search.Options{
+		ConfigFile:    path,
+		Profile:       "lines",
+		Normalization: "rank",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, path, result.ConfigFile)
+	assert.Equal(t, "lines", result.Scoring.Name)
+	assert.Equal(t, normalize.Rank, result.Scoring.Normalization)
+
+	_, err = search.SearchWithOptions([]string{"testdata"}, search.Options{
+		ConfigFile: path,
+		Profile:    "missing",
+	})
+	require.Error(t, err)
+}
+
+func TestSearchOptions_Profile(t *testing.T) {
+	fuzz, err := search.SearchWithOptions([]string{"../callgraph/testdata/golang"}, search.Options{Profile: "fuzz"})
+	require.NoError(t, err)
+	unit, err := search.SearchWithOptions([]string{"../callgraph/testdata/golang"}, search.Options{Profile: "unit"})
+	require.NoError(t, err)
+	require.Equal(t, len(fuzz), len(unit))
+
+	scores := map[string]float64{}
+	for _, c := range fuzz {
+		scores[c.String()] = c.Score
+	}
+	changed := 0
+	for _, c := range unit {
+		if c.Score != scores[c.String()] {
+			changed++
+		}
+	}
+	assert.NotZero(t, changed, "the profiles should lead to different scores")
+}
+
+func TestSearchOptions_Filters(t *testing.T) {
+	testCases := map[string]struct {
+		opts     search.Options
+		expected []string
+	}{
+		"expression": {
+			opts:     search.Options{FilterExpression: `language == "Go" && visibility == "private" && metrics.loc > 0`},
+			expected: []string{"helper", "tokenize", "validate"},
+		},
+		"expression_class": {
+			opts:     search.Options{FilterExpression: `class == "Parser" && !has_fuzz_test`},
+			expected: []string{"Parse", "tokenize"},
+		},
+		"visibility": {
+			opts:     search.Options{Visibility: []string{"Public"}},
+			expected: []string{"Check", "Parse", "Run", "Serve"},
+		},
+		"exclude_func": {
+			opts:     search.Options{ExcludeFunc: "^(Run|Serve|validate)$"},
+			expected: []string{"Check", "Parse", "helper", "tokenize"},
+		},
+		"include_dir": {
+			opts:     search.Options{IncludePaths: []string{"util/"}},
+			expected: []string{"Check", "helper"},
+		},
+		"include_glob": {
+			opts:     search.Options{IncludePaths: []string{"*/*.go"}},
+			expected: []string{"Check", "helper"},
+		},
+		"include_base": {
+			opts:     search.Options{IncludePaths: []string{"main.go"}},
+			expected: []string{"Parse", "Run", "Serve", "tokenize", "validate"},
+		},
+		"combined": {
+			opts:     search.Options{Visibility: []string{"private"}, IncludePaths: []string{"main.go"}},
+			expected: []string{"tokenize", "validate"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			candidates, err := search.SearchWithOptions([]string{"../callgraph/testdata/golang"}, tc.opts)
+			require.NoError(t, err)
+			names := []string{}
+			for _, c := range candidates {
+				names = append(names, c.Function.Name)
+			}
+			assert.ElementsMatch(t, tc.expected, names)
+		})
+	}
+}
+
+func TestSearchOptions_MinScore(t *testing.T) {
+	all, err := search.Search([]string{"../callgraph/testdata/golang"})
+	require.NoError(t, err)
+	require.Greater(t, len(all), 2)
+
+	// the scores do not depend on the filters
+	minScore := all[1].Score
+	candidates, err := search.SearchWithOptions([]string{"../callgraph/testdata/golang"}, search.Options{MinScore: &minScore})
+	require.NoError(t, err)
+	require.NotEmpty(t, candidates)
+	assert.Less(t, len(candidates), len(all))
+	for i, c := range candidates {
+		assert.GreaterOrEqual(t, c.Score, minScore)
+		assert.Equal(t, all[i].Score, c.Score)
+	}
+}
+
+func TestSearchOptions_InvalidFilters(t *testing.T) {
+	testCases := map[string]search.Options{
+		"syntax":     {FilterExpression: "metrics.cc >"},
+		"variable":   {FilterExpression: "metrics.unknown > 1"},
+		"type":       {FilterExpression: "language > 1"},
+		"visibility": {Visibility: []string{"internal"}},
+		"regex":      {ExcludeFunc: "("},
+		"glob":       {IncludePaths: []string{"["}},
+	}
+
+	for name, opts := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := search.SearchWithOptions([]string{"../callgraph/testdata/golang"}, opts)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSearchOptions_Jobs(t *testing.T) {
+	report := filepath.Join(t.TempDir(), "cover.out")
+	require.NoError(t, os.WriteFile(report, []byte("mode: set\ngithub.com/jochil/gcs/pkg/expr/expr.go:10.1,20.2 3 1\n"), 0o644))
+
+	run := func(jobs int) candidate.Candidates {
+		candidates, err := search.SearchWithOptions([]string{"../expr", "../normalize", "testdata"}, search.Options{
+			CoverageFiles: []string{report},
+			Jobs:          jobs,
+		})
+		require.NoError(t, err)
+		return candidates
+	}
+
+	sequential := run(1)
+	require.NotEmpty(t, sequential)
+	for i := 0; i < 3; i++ {
+		parallel := run(8)
+		require.Len(t, parallel, len(sequential))
+		for j := range sequential {
+			assert.Equal(t, sequential[j].String(), parallel[j].String())
+			assert.Equal(t, sequential[j].Path, parallel[j].Path)
+			assert.Equal(t, sequential[j].Score, parallel[j].Score)
+		}
+	}
+}
+
+func TestScan_Skipped(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n\nfunc A(s string) {}\n"), 0o644))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "missing.go"), filepath.Join(dir, "b.go")))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "missing_test.go"), filepath.Join(dir, "b_test.go")))
+
+	result, err := search.Scan([]string{dir}, search.Options{})
+	require.NoError(t, err)
+	require.Len(t, result.Candidates, 1)
+	assert.Equal(t, "A", result.Candidates[0].Function.Name)
+
+	require.Len(t, result.Skipped, 2)
+	assert.Equal(t, filepath.Join(dir, "b.go"), result.Skipped[0].Path)
+	assert.Equal(t, filepath.Join(dir, "b_test.go"), result.Skipped[1].Path)
+	for _, skipped := range result.Skipped {
+		require.ErrorIs(t, skipped, fs.ErrNotExist)
+	}
+
+	// the skipped files are only logged
+	candidates, err := search.SearchWithOptions([]string{dir}, search.Options{})
+	require.NoError(t, err)
+	assert.Len(t, candidates, 1)
+}
+
+func TestScanContext_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := search.ScanContext(ctx, []string{"testdata"}, search.Options{})
+	require.ErrorIs(t, err, context.Canceled)
+
+	// canceled while parsing
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	_, err = search.SearchContext(ctx, []string{"testdata"}, search.Options{
+		Jobs: 1,
+		Progress: func(p search.Progress) {
+			if p.Parsed == 1 {
+				cancel()
+			}
+		},
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestScanContext_FileTimeout(t *testing.T) {
+	result, err := search.ScanContext(context.Background(), []string{"testdata"}, search.Options{FileTimeout: time.Nanosecond})
+	require.NoError(t, err)
+	assert.Empty(t, result.Candidates)
+	require.Len(t, result.Skipped, 3)
+	for _, skipped := range result.Skipped {
+		require.ErrorIs(t, skipped, context.DeadlineExceeded)
+		assert.Contains(t, skipped.Error(), "timeout")
+	}
+}
+
+func TestScan_Progress(t *testing.T) {
+	updates := []search.Progress{}
+	result, err := search.Scan([]string{"testdata"}, search.Options{
+		Progress: func(p search.Progress) {
+			updates = append(updates, p)
+		},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, updates)
+
+	last := updates[len(updates)-1]
+	assert.Equal(t, search.PhaseDone, last.Phase)
+	assert.Equal(t, 3, last.Discovered)
+	assert.Equal(t, 3, last.Parsed)
+	assert.Equal(t, len(result.Candidates), last.Candidates)
+
+	phases := []search.Phase{}
+	for i, p := range updates {
+		if len(phases) == 0 || phases[len(phases)-1] != p.Phase {
+			phases = append(phases, p.Phase)
+		}
+		if i > 0 {
+			assert.GreaterOrEqual(t, p.Parsed, updates[i-1].Parsed)
+			assert.GreaterOrEqual(t, p.Candidates, updates[i-1].Candidates)
+		}
+	}
+	assert.Equal(t, []search.Phase{search.PhaseWalk, search.PhaseParse, search.PhaseScore, search.PhaseDone}, phases)
+}
+
+func TestScan_SlowProgress(t *testing.T) {
+	var running atomic.Int32
+	var last search.Progress
+	_, err := search.Scan([]string{"testdata"}, search.Options{
+		Jobs: 4,
+		Progress: func(p search.Progress) {
+			require.Equal(t, int32(1), running.Add(1), "callback called concurrently")
+			defer running.Add(-1)
+			time.Sleep(5 * time.Millisecond)
+			last = p
+		},
+	})
+	require.NoError(t, err)
+
+	// the merged updates end with the final progress
+	assert.Equal(t, search.PhaseDone, last.Phase)
+	assert.Equal(t, 3, last.Parsed)
+}
+
+func TestScan_Graph(t *testing.T) {
+	all, err := search.Scan([]string{"../callgraph/testdata/golang"}, search.Options{})
+	require.NoError(t, err)
+
+	result, err := search.Scan([]string{"../callgraph/testdata/golang"}, search.Options{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, result.Candidates, 1)
+	require.NotNil(t, result.Graph)
+
+	// the graph contains the candidates removed by the limit
+	order, err := result.Graph.Order()
+	require.NoError(t, err)
+	assert.Equal(t, len(all.Candidates), order)
+}
+
+func TestSearch_CheckoutBelowTestDirectory(t *testing.T) {
+	// the directories above the scanned path do not mark the files as tests
+	repo := filepath.Join(t.TempDir(), "src", "test", "repo")
+	for _, dir := range []string{"src/main/java", "src/test/java"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(repo, dir), 0o755))
+	}
+	source := "class Foo {\n  void parse(String s) {\n  }\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "src/main/java/Foo.java"), []byte(source), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "src/test/java/Helper.java"), []byte(source), 0o644))
+
+	for _, path := range []string{repo, filepath.Join(repo, "src")} {
+		candidates, err := search.SearchWithOptions([]string{path}, search.Options{})
+		require.NoError(t, err)
+		require.Len(t, candidates, 1, path)
+		assert.Equal(t, filepath.Join(repo, "src/main/java/Foo.java"), candidates[0].Path)
+	}
+}
@@ -0,0 +1,78 @@
+package search
+
+import (
+	"context"
+
+	"github.com/jochil/gcs/pkg/callgraph"
+	"github.com/jochil/gcs/pkg/candidate"
+)
+
+// Stream is a running search sending the candidates as soon as their files are parsed
+type Stream struct {
+	ctx        context.Context
+	scanner    *scanner
+	candidates chan *candidate.Candidate
+	done       chan struct{}
+
+	// set once all files are parsed
+	parsed  candidate.Candidates
+	graph   *callgraph.Builder
+	skipped []*FileError
+	err     error
+}
+
+// NewStream starts a search sending every candidate with its raw metrics (eg. complexity, sinks, linked tests)
+// right after its file is parsed, so results can be processed before the whole tree is parsed. The AST and the
+// source of the candidates are released after calculating the metrics, the memory does not grow with the
+// parsed files. The metrics depending on all candidates (eg. the call graph, custom metrics) and the scores are
+// calculated by Score. Invalid options are returned right away, errors of the search by Score.
+//
+// The candidates have to be received (or Score called) until the channel is closed, otherwise the search
+// blocks until the context is done.
+func NewStream(ctx context.Context, srcPaths []string, opts Options) (*Stream, error) {
+	s, err := newScanner(srcPaths, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := &Stream{
+		ctx:        ctx,
+		scanner:    s,
+		candidates: make(chan *candidate.Candidate),
+		done:       make(chan struct{}),
+	}
+	go func() {
+		defer close(stream.done)
+		defer close(stream.candidates)
+		stream.parsed, stream.graph, stream.skipped, stream.err = s.parse(ctx, func(candidates candidate.Candidates) {
+			for _, c := range candidates {
+				select {
+				case stream.candidates <- c:
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+	}()
+	return stream, nil
+}
+
+// Candidates returns the candidates in the order their files are parsed, the channel is closed once all files
+// are parsed or the context is done. The candidates are not filtered and have no score yet.
+func (s *Stream) Candidates() <-chan *candidate.Candidate {
+	return s.candidates
+}
+
+// Score waits until all files are parsed and calculates the remaining metrics and the scores of all candidates,
+// including the ones not received yet. The received candidates are updated, the result is filtered, sorted and
+// limited like the result of Scan. The scoring stops with the error of the context if it is done.
+func (s *Stream) Score() (*Result, error) {
+	for range s.candidates {
+		// not received candidates are scored anyway
+	}
+	<-s.done
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.scanner.score(s.ctx, s.parsed, s.graph, s.skipped)
+}
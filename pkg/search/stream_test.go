@@ -0,0 +1,88 @@
+package search_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jochil/gcs/pkg/candidate"
+	"github.com/jochil/gcs/pkg/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream(t *testing.T) {
+	expected, err := search.Scan([]string{"testdata"}, search.Options{})
+	require.NoError(t, err)
+
+	stream, err := search.NewStream(context.Background(), []string{"testdata"}, search.Options{})
+	require.NoError(t, err)
+
+	received := candidate.Candidates{}
+	for c := range stream.Candidates() {
+		// raw metrics and linked tests, but no score and no AST
+		require.NotNil(t, c.Metrics)
+		assert.Nil(t, c.AST)
+		assert.Nil(t, c.Source)
+		assert.NotEmpty(t, c.Code)
+		assert.Zero(t, c.Score)
+		assert.Equal(t, c.Function.Name == "a", c.Metrics.HasFuzzTest, "wrong fuzz test for %s", c.Function.Name)
+		received = append(received, c)
+	}
+	assert.Len(t, received, len(expected.Candidates))
+
+	result, err := stream.Score()
+	require.NoError(t, err)
+	require.Len(t, result.Candidates, len(expected.Candidates))
+	for i, c := range result.Candidates {
+		assert.Equal(t, expected.Candidates[i].String(), c.String())
+		assert.Equal(t, expected.Candidates[i].Score, c.Score, "wrong score for %s", c)
+		assert.Equal(t, expected.Candidates[i].Metrics, c.Metrics, "wrong metrics for %s", c)
+		assert.Contains(t, received, c)
+	}
+}
+
+func TestStream_Options(t *testing.T) {
+	stream, err := search.NewStream(context.Background(), []string{"testdata"}, search.Options{
+		HideFuzzed: true,
+		Limit:      2,
+	})
+	require.NoError(t, err)
+
+	// the candidates are scored without receiving them
+	result, err := stream.Score()
+	require.NoError(t, err)
+	require.Len(t, result.Candidates, 2)
+	for _, c := range result.Candidates {
+		assert.False(t, c.Metrics.HasFuzzTest)
+	}
+
+	_, err = search.NewStream(context.Background(), []string{"testdata"}, search.Options{Profile: "unknown"})
+	require.Error(t, err)
+}
+
+func TestStream_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := search.NewStream(ctx, []string{"testdata"}, search.Options{Jobs: 1})
+	require.NoError(t, err)
+
+	// stop receiving after the first candidate
+	<-stream.Candidates()
+	cancel()
+
+	_, err = stream.Score()
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestStream_CanceledScoring(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := search.NewStream(ctx, []string{"testdata"}, search.Options{})
+	require.NoError(t, err)
+
+	// all files are parsed, only the scoring is canceled
+	for range stream.Candidates() {
+	}
+	cancel()
+
+	_, err = stream.Score()
+	require.ErrorIs(t, err, context.Canceled)
+}
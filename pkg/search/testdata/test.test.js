@@ -0,0 +1,5 @@
+const { a } = require("./test");
+
+it.fuzz("fuzz a", (data) => {
+  a(data);
+});
@@ -0,0 +1,101 @@
+package sinks
+
+// Default is the built-in catalog of sinks
+var Default = Catalog{
+	{
+		Name:   "unsafe",
+		Weight: 3,
+		Patterns: map[string][]string{
+			"go": {"unsafe", "C"},
+		},
+	},
+	{
+		Name:   "reflection",
+		Weight: 2,
+		Patterns: map[string][]string{
+			"go":         {"reflect"},
+			"java":       {"Class.forName", "*.getMethod", "*.getDeclaredMethod", "*.invoke", "*.newInstance"},
+			"javascript": {"Reflect.apply", "Reflect.construct"},
+		},
+	},
+	{
+		Name:   "binary",
+		Weight: 3,
+		Patterns: map[string][]string{
+			"go":         {"encoding/binary", "encoding/gob", "encoding/asn1"},
+			"java":       {"ByteBuffer.wrap", "new DataInputStream"},
+			"javascript": {"Buffer.from", "new DataView"},
+		},
+	},
+	{
+		Name:   "conversion",
+		Weight: 1,
+		Patterns: map[string][]string{
+			"go":         {"strconv"},
+			"java":       {"Integer.parseInt", "Integer.valueOf", "Long.parseLong", "Double.parseDouble", "Float.parseFloat"},
+			"javascript": {"parseInt", "parseFloat"},
+			"c":          {"atoi", "atol", "atof", "strtol", "strtoul", "strtod"},
+		},
+	},
+	{
+		Name:   "regex",
+		Weight: 2,
+		Patterns: map[string][]string{
+			"go":         {"regexp.Compile", "regexp.MustCompile", "regexp.MatchString"},
+			"java":       {"Pattern.compile", "Pattern.matches"},
+			"javascript": {"new RegExp", "RegExp"},
+			"c":          {"regcomp"},
+		},
+	},
+	{
+		Name:   "exec",
+		Weight: 5,
+		Patterns: map[string][]string{
+			"go": {"os/exec", "syscall.Exec"},
+			// Runtime.getRuntime().exec() is matched by its leading identifier, a bare "*.exec" would match
+			// every exec method (eg. RegExp.exec in javascript)
+			"java": {"Runtime.exec", "new ProcessBuilder"},
+			// the child_process functions without a generic name can be imported by destructuring
+			"javascript": {
+				"child_process.exec", "child_process.execSync", "child_process.execFile", "child_process.execFileSync",
+				"child_process.spawn", "child_process.spawnSync", "cp.exec", "cp.execSync", "cp.execFile",
+				"cp.execFileSync", "cp.spawn", "cp.spawnSync", "execSync", "execFileSync", "spawnSync",
+			},
+			"c": {"system", "popen", "execl", "execlp", "execv", "execve", "execvp"},
+		},
+	},
+	{
+		Name:   "sql",
+		Weight: 4,
+		Patterns: map[string][]string{
+			"go":         {"*.Query", "*.QueryRow", "*.QueryContext", "*.QueryRowContext", "*.Exec", "*.ExecContext", "*.Prepare"},
+			"java":       {"*.executeQuery", "*.executeUpdate", "*.prepareStatement", "*.createQuery", "*.createNativeQuery"},
+			"javascript": {"*.query", "*.raw"},
+			"c":          {"sqlite3_exec", "sqlite3_prepare_v2", "mysql_query"},
+		},
+	},
+	{
+		Name:   "deserialization",
+		Weight: 4,
+		Patterns: map[string][]string{
+			"go":         {"encoding/json.Unmarshal", "encoding/xml.Unmarshal", "gopkg.in/yaml.v3.Unmarshal", "encoding/json.Decoder.Decode", "encoding/xml.Decoder.Decode", "gopkg.in/yaml.v3.Decoder.Decode"},
+			"java":       {"new ObjectInputStream", "*.readObject", "new XMLDecoder", "*.readValue", "*.fromJson"},
+			"javascript": {"JSON.parse", "*.deserialize", "*.unserialize"},
+		},
+	},
+	{
+		Name:   "eval",
+		Weight: 5,
+		Patterns: map[string][]string{
+			"java":       {"*.eval"},
+			"javascript": {"eval", "new Function", "vm.runInContext", "vm.runInNewContext"},
+		},
+	},
+	{
+		Name:   "memory",
+		Weight: 5,
+		Patterns: map[string][]string{
+			"c": {"memcpy", "memmove", "strcpy", "strncpy", "strcat", "strncat", "sprintf", "vsprintf", "gets", "scanf", "sscanf", "alloca"},
+		},
+	},
+}
@@ -0,0 +1,347 @@
+// Package sinks detects calls of interesting functions (eg. parsing, code execution, memory access)
+// inside of a function, these are good indicators for valuable fuzz targets.
+package sinks
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jochil/gcs/pkg/helper"
+	"github.com/jochil/gcs/pkg/types"
+	sitter "github.com/smacker/go-tree-sitter"
+	"gopkg.in/yaml.v3"
+)
+
+// Category groups sinks of the same kind, the patterns are listed per language.
+//
+// A pattern matches
+//   - a function called without qualifier (eg. "eval", "memcpy")
+//   - a qualified call (eg. "regexp.Compile", "JSON.parse"), for go the import path is used as qualifier
+//   - every call of a go package (eg. "os/exec")
+//   - a method with any receiver (eg. "*.readObject")
+//   - a method of a go type (eg. "encoding/json.Decoder.Decode"), the type of the receiver is taken from the
+//     declaration of the variable or the NewX function creating it
+//   - a constructor call (eg. "new ObjectInputStream")
+//
+// A call only counts for the categories of its most specific matching pattern, eg. syscall.Exec matches
+// "syscall.Exec" but not "*.Exec".
+type Category struct {
+	Name     string              `yaml:"name"`
+	Weight   float64             `yaml:"weight"`
+	Patterns map[string][]string `yaml:"patterns"`
+}
+
+// Catalog is a list of sink categories
+type Catalog []*Category
+
+// Load reads a yaml file containing additional categories and merges them into a copy of the default catalog.
+// Patterns of existing categories are appended, a weight > 0 replaces the default weight.
+func Load(path string) (Catalog, error) {
+	categories, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Default.Merge(categories...), nil
+}
+
+// ReadFile reads the categories of a yaml file without merging them
+func ReadFile(path string) ([]*Category, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	categories := []*Category{}
+	if err := yaml.Unmarshal(data, &categories); err != nil {
+		return nil, fmt.Errorf("unable to read sink catalog %s: %w", path, err)
+	}
+	return categories, nil
+}
+
+// Merge returns a copy of the catalog extended by the given categories
+func (catalog Catalog) Merge(categories ...*Category) Catalog {
+	merged := Catalog{}
+	for _, c := range catalog {
+		merged = append(merged, c.clone())
+	}
+
+	for _, add := range categories {
+		i := slices.IndexFunc(merged, func(c *Category) bool { return c.Name == add.Name })
+		if i == -1 {
+			merged = append(merged, add.clone())
+			continue
+		}
+		if add.Weight > 0 {
+			merged[i].Weight = add.Weight
+		}
+		for lang, patterns := range add.Patterns {
+			merged[i].Patterns[strings.ToLower(lang)] = append(merged[i].Patterns[strings.ToLower(lang)], patterns...)
+		}
+	}
+	return merged
+}
+
+func (c *Category) clone() *Category {
+	clone := &Category{Name: c.Name, Weight: c.Weight, Patterns: map[string][]string{}}
+	for lang, patterns := range c.Patterns {
+		clone.Patterns[strings.ToLower(lang)] = slices.Clone(patterns)
+	}
+	return clone
+}
+
+// Weighted returns the sum of the sink calls multiplied by the weight of their category
+func (catalog Catalog) Weighted(counts map[string]int) float64 {
+	weighted := 0.0
+	for _, c := range catalog {
+		weighted += float64(counts[c.Name]) * c.Weight
+	}
+	return weighted
+}
+
+// Call is a call of a sink found in the AST
+type Call struct {
+	Node       *sitter.Node
+	Categories []string
+}
+
+// Calls returns all sink calls inside of a node
+func (catalog Catalog) Calls(node *sitter.Node, source []byte, language types.Language) []*Call {
+	calls := []*Call{}
+	if node == nil || source == nil {
+		return calls
+	}
+
+	imports := map[string]string{}
+	variables := map[string]string{}
+	if language == types.Go {
+		imports = helper.GoImports(node, source)
+		variables = goVariableTypes(node, source)
+	}
+
+	for _, call := range findCalls(node, source) {
+		if language == types.Go {
+			call.receiverType = goReceiverType(call.receiver, source, variables)
+		}
+		categories := []string{}
+		for _, key := range call.keys(imports) {
+			for _, c := range catalog {
+				if slices.Contains(c.patterns(language), key) && !slices.Contains(categories, c.Name) {
+					categories = append(categories, c.Name)
+				}
+			}
+			if len(categories) > 0 {
+				// less specific patterns are ignored
+				break
+			}
+		}
+		if len(categories) > 0 {
+			calls = append(calls, &Call{Node: call.node, Categories: categories})
+		}
+	}
+	return calls
+}
+
+// Count returns the number of sink calls per category inside of a node
+func (catalog Catalog) Count(node *sitter.Node, source []byte, language types.Language) map[string]int {
+	counts := map[string]int{}
+	for _, call := range catalog.Calls(node, source, language) {
+		for _, category := range call.Categories {
+			counts[category]++
+		}
+	}
+	return counts
+}
+
+// returns the patterns of a language, typescript uses the javascript patterns as well
+func (c *Category) patterns(language types.Language) []string {
+	patterns := c.Patterns[strings.ToLower(language.String())]
+	if language == types.TypeScript {
+		patterns = append(slices.Clone(patterns), c.Patterns[strings.ToLower(types.JavaScript.String())]...)
+	}
+	return patterns
+}
+
+// Categories returns the sorted names of all categories with at least one call
+func Categories(counts map[string]int) []string {
+	names := []string{}
+	for name, count := range counts {
+		if count > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+type call struct {
+	node *sitter.Node
+	// receiver is the expression the method is called on
+	receiver *sitter.Node
+	// qualifier is the leading identifier of the called expression (eg. "Runtime" for Runtime.getRuntime().exec())
+	qualifier string
+	// receiverType is the qualified go type of the receiver (eg. "json.Decoder"), empty if unknown
+	receiverType string
+	name         string
+	// constructor call (new X())
+	constructor bool
+	// the call is made on the result of another call or index expression (eg. exec.Command().Run())
+	chained bool
+}
+
+// returns all keys a pattern can match, the most specific first
+func (c call) keys(imports map[string]string) []string {
+	if c.constructor {
+		return []string{"new " + c.name}
+	}
+	if c.qualifier == "" {
+		return []string{c.name}
+	}
+
+	keys := []string{}
+	if pkg, typeName, ok := strings.Cut(c.receiverType, "."); ok {
+		if path, ok := imports[pkg]; ok {
+			keys = append(keys, path+"."+typeName+"."+c.name)
+		}
+	}
+	path, imported := imports[c.qualifier]
+	imported = imported && !c.chained
+	if imported {
+		keys = append(keys, path+"."+c.name)
+	}
+	keys = append(keys, c.qualifier+"."+c.name)
+	if imported {
+		keys = append(keys, path)
+	}
+	return append(keys, "*."+c.name)
+}
+
+func findCalls(node *sitter.Node, source []byte) []call {
+	calls := []call{}
+
+	var visit func(*sitter.Node)
+	visit = func(n *sitter.Node) {
+		switch n.Type() {
+		case "call_expression":
+			callee := n.ChildByFieldName("function")
+			switch {
+			case callee == nil:
+			case callee.Type() == "identifier":
+				calls = append(calls, call{node: n, name: callee.Content(source)})
+			case callee.Type() == "selector_expression":
+				calls = appendQualified(calls, n, callee.ChildByFieldName("operand"), callee.ChildByFieldName("field"), source)
+			case callee.Type() == "member_expression":
+				calls = appendQualified(calls, n, callee.ChildByFieldName("object"), callee.ChildByFieldName("property"), source)
+			}
+		case "method_invocation":
+			calls = appendQualified(calls, n, n.ChildByFieldName("object"), n.ChildByFieldName("name"), source)
+		case "object_creation_expression":
+			if t := n.ChildByFieldName("type"); t != nil {
+				calls = append(calls, call{node: n, name: t.Content(source), constructor: true})
+			}
+		case "new_expression":
+			if t := n.ChildByFieldName("constructor"); t != nil {
+				calls = append(calls, call{node: n, name: t.Content(source), constructor: true})
+			}
+		}
+
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			visit(n.NamedChild(i))
+		}
+	}
+	visit(node)
+	return calls
+}
+
+func appendQualified(calls []call, node, qualifier, name *sitter.Node, source []byte) []call {
+	if name == nil {
+		return calls
+	}
+	c := call{node: node, receiver: qualifier, name: name.Content(source)}
+	if qualifier != nil {
+		q := qualifier.Content(source)
+		// package variables (eg. binary.BigEndian) are no chained calls
+		c.chained = strings.ContainsAny(q, "([")
+		if i := strings.IndexAny(q, ".(["); i != -1 {
+			q = q[:i]
+		}
+		c.qualifier = q
+	}
+	return append(calls, c)
+}
+
+// returns the types of the go variables and parameters declared with a qualified type (eg. "dec *json.Decoder")
+// or created by a NewX function of a package (eg. "dec := json.NewDecoder(r)"), flow-insensitive
+func goVariableTypes(node *sitter.Node, source []byte) map[string]string {
+	variables := map[string]string{}
+
+	var visit func(*sitter.Node)
+	visit = func(n *sitter.Node) {
+		switch n.Type() {
+		case "parameter_declaration", "var_spec":
+			if typeName := qualifiedType(n.ChildByFieldName("type"), source); typeName != "" {
+				for _, name := range helper.ChildrenByType(n, "identifier") {
+					variables[name.Content(source)] = typeName
+				}
+			}
+		case "short_var_declaration", "assignment_statement":
+			left, right := n.ChildByFieldName("left"), n.ChildByFieldName("right")
+			if left == nil || right == nil || left.NamedChildCount() != right.NamedChildCount() {
+				break
+			}
+			for i := 0; i < int(left.NamedChildCount()); i++ {
+				if typeName := constructedType(right.NamedChild(i), source); typeName != "" && left.NamedChild(i).Type() == "identifier" {
+					variables[left.NamedChild(i).Content(source)] = typeName
+				}
+			}
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			visit(n.NamedChild(i))
+		}
+	}
+	visit(node)
+	return variables
+}
+
+// returns the qualified type of a method receiver, a variable or the result of a NewX function
+func goReceiverType(receiver *sitter.Node, source []byte, variables map[string]string) string {
+	if receiver == nil {
+		return ""
+	}
+	if receiver.Type() == "identifier" {
+		return variables[receiver.Content(source)]
+	}
+	return constructedType(receiver, source)
+}
+
+// returns the qualified type of a (pointer) type node (eg. "json.Decoder" for *json.Decoder)
+func qualifiedType(node *sitter.Node, source []byte) string {
+	if node != nil && node.Type() == "pointer_type" && node.NamedChildCount() > 0 {
+		node = node.NamedChild(0)
+	}
+	if node == nil || node.Type() != "qualified_type" {
+		return ""
+	}
+	return node.Content(source)
+}
+
+// returns the type created by a NewX function of a package (eg. "json.Decoder" for json.NewDecoder(r))
+func constructedType(node *sitter.Node, source []byte) string {
+	if node == nil || node.Type() != "call_expression" {
+		return ""
+	}
+	function := node.ChildByFieldName("function")
+	if function == nil || function.Type() != "selector_expression" {
+		return ""
+	}
+	pkg, field := function.ChildByFieldName("operand"), function.ChildByFieldName("field")
+	if pkg == nil || field == nil || pkg.Type() != "identifier" {
+		return ""
+	}
+	typeName, ok := strings.CutPrefix(field.Content(source), "New")
+	if !ok || typeName == "" {
+		return ""
+	}
+	return pkg.Content(source) + "." + typeName
+}
@@ -0,0 +1,97 @@
+package sinks_test
+
+import (
+	"testing"
+
+	"github.com/jochil/gcs/pkg/parser"
+	"github.com/jochil/gcs/pkg/sinks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCount(t *testing.T) {
+	testCases := map[string]struct {
+		path     string
+		expected map[string]int
+	}{
+		// syscall.Exec is not counted as sql by "*.Exec" and only the Decode calls of decoders are counted
+		"go": {
+			path: "testdata/a.go",
+			expected: map[string]int{
+				"conversion":      2,
+				"binary":          1,
+				"unsafe":          1,
+				"regex":           1,
+				"exec":            2,
+				"deserialization": 4,
+				"sql":             1,
+			},
+		},
+		"java": {
+			path: "testdata/A.java",
+			expected: map[string]int{
+				"exec":            1,
+				"conversion":      1,
+				"deserialization": 2,
+				"sql":             1,
+			},
+		},
+		// re.exec is not counted as exec
+		"javascript": {
+			path: "testdata/a.js",
+			expected: map[string]int{
+				"deserialization": 1,
+				"eval":            1,
+				"regex":           1,
+				"exec":            2,
+				"conversion":      1,
+			},
+		},
+		"c": {
+			path: "testdata/a.c",
+			expected: map[string]int{
+				"memory":     2,
+				"conversion": 1,
+				"exec":       1,
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			candidates, err := parser.ParseFile(tc.path)
+			require.NoError(t, err)
+			require.Len(t, candidates, 1)
+			c := candidates[0]
+			assert.Equal(t, tc.expected, sinks.Default.Count(c.AST, c.Source, c.Language))
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	catalog, err := sinks.Load("testdata/extra.yaml")
+	require.NoError(t, err)
+	require.Len(t, catalog, len(sinks.Default)+1)
+
+	assert.Equal(t, 1.0, catalog.Weighted(map[string]int{"crypto": 1}))
+	assert.Equal(t, 20.0, catalog.Weighted(map[string]int{"exec": 2}))
+	// the default catalog is not modified
+	assert.Equal(t, 10.0, sinks.Default.Weighted(map[string]int{"exec": 2}))
+
+	for _, c := range catalog {
+		switch c.Name {
+		case "exec":
+			assert.Contains(t, c.Patterns["go"], "os/exec")
+			assert.Contains(t, c.Patterns["go"], "syscall.ForkExec")
+		case "crypto":
+			assert.Equal(t, []string{"crypto/aes"}, c.Patterns["go"])
+		}
+	}
+
+	_, err = sinks.Load("testdata/missing.yaml")
+	require.Error(t, err)
+}
+
+func TestCategories(t *testing.T) {
+	assert.Equal(t, []string{"exec", "sql"}, sinks.Categories(map[string]int{"sql": 1, "exec": 2, "regex": 0}))
+}
@@ -0,0 +1,9 @@
+class A {
+    Object a(String cmd, byte[] data) throws Exception {
+        Runtime.getRuntime().exec(cmd);
+        int n = Integer.parseInt(cmd);
+        ObjectInputStream in = new ObjectInputStream(new ByteArrayInputStream(data));
+        stmt.executeQuery("SELECT " + n);
+        return in.readObject();
+    }
+}
@@ -0,0 +1,6 @@
+int a(char *input) {
+    char buf[16];
+    strcpy(buf, input);
+    memcpy(buf, input, 8);
+    return atoi(buf) + system(input);
+}
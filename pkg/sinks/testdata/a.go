@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"os/exec"
+	re "regexp"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+func A(data []byte, db *DB, d *json.Decoder) error {
+	n, _ := strconv.Atoi(string(data))
+	size := binary.BigEndian.Uint32(data)
+	ptr := unsafe.Pointer(&data[0])
+	_ = re.MustCompile(string(data))
+	_ = exec.Command("ls", string(data)).Run()
+	_ = syscall.Exec("/bin/sh", nil, nil)
+	var v map[string]any
+	_ = json.Unmarshal(data, &v)
+	_ = json.NewDecoder(bytes.NewReader(data)).Decode(&v)
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	_ = dec.Decode(&v)
+	_ = d.Decode(&v)
+	// unknown receiver
+	_ = db.Decode(&v)
+	db.Query("SELECT * FROM t WHERE id = " + strconv.Itoa(n))
+	_, _ = size, ptr
+	return nil
+}
@@ -0,0 +1,9 @@
+function a(input) {
+  const obj = JSON.parse(input);
+  eval(obj.code);
+  const re = new RegExp(obj.pattern);
+  // regex matching is no command execution
+  const match = re.exec(input);
+  cp.spawn(obj.bin);
+  return child_process.execSync(obj.cmd) + parseInt(obj.n) + match;
+}
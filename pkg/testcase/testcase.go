@@ -0,0 +1,282 @@
+// Package testcase finds existing unit tests and fuzz targets together with the functions they call
+package testcase
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jochil/gcs/pkg/helper"
+	"github.com/jochil/gcs/pkg/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+type Kind string
+
+const (
+	Unit Kind = "unit"
+	Fuzz Kind = "fuzz"
+)
+
+// Test is a unit test or fuzz target found in a test file
+type Test struct {
+	Name     string         `json:"name"`
+	Kind     Kind           `json:"kind"`
+	Path     string         `json:"path"`
+	Language types.Language `json:"language"`
+	// Calls are the names of all functions/methods called by the test
+	Calls []string `json:"calls"`
+	// Package is the package of a java test file
+	Package string `json:"package,omitempty"`
+	// Imports of the test file indexed by the name used in the code (see helper.Imports)
+	Imports map[string]string `json:"imports,omitempty"`
+}
+
+// java annotations marking unit tests and fuzz tests (junit + jazzer)
+var (
+	javaUnitAnnotations = []string{"Test", "ParameterizedTest", "RepeatedTest", "TestFactory", "TestTemplate"}
+	javaFuzzAnnotations = []string{"FuzzTest"}
+)
+
+// javascript functions defining tests (jest + mocha), jazzer.js fuzz tests are defined via it.fuzz/test.fuzz
+var jsTestFunctions = []string{"it", "test"}
+
+type testParser struct {
+	path     string
+	source   []byte
+	language types.Language
+	pkg      string
+	imports  map[string]string
+	tests    []*Test
+}
+
+// Parse returns all tests and fuzz targets of a test file
+func Parse(path string, language types.Language) ([]*Test, error) {
+	return ParseWith(context.Background(), sitter.NewParser(), path, language)
+}
+
+// ParseWith is like Parse but reuses an existing tree-sitter parser (eg. one per worker), the deadline of
+// the context limits the parsing time
+func ParseWith(ctx context.Context, parser *sitter.Parser, path string, language types.Language) ([]*Test, error) {
+	if err := helper.ContextErr(ctx); err != nil {
+		return nil, err
+	}
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	parser.SetLanguage(helper.SitterLanguages[language])
+	tree, err := helper.Parse(ctx, parser, source)
+	if err != nil {
+		return nil, err
+	}
+
+	root := tree.RootNode()
+	p := &testParser{
+		path:     path,
+		source:   source,
+		language: language,
+		pkg:      javaPackage(root, source),
+		imports:  helper.Imports(root, source, language),
+		tests:    []*Test{},
+	}
+	switch language {
+	case types.Go:
+		p.findGo(root)
+	case types.Java:
+		p.findJava(root)
+	case types.JavaScript, types.TypeScript:
+		p.findJS(root, "")
+	default:
+		slog.Debug("tests not supported", "language", language, "path", path)
+	}
+	return p.tests, nil
+}
+
+// go: func TestXxx(t *testing.T) and func FuzzXxx(f *testing.F)
+func (p *testParser) findGo(root *sitter.Node) {
+	for _, fn := range helper.ChildrenByType(root, "function_declaration") {
+		name := fn.ChildByFieldName("name").Content(p.source)
+		var kind Kind
+		switch {
+		case strings.HasPrefix(name, "Fuzz"):
+			kind = Fuzz
+		case strings.HasPrefix(name, "Test") && name != "TestMain":
+			kind = Unit
+		default:
+			continue
+		}
+
+		// calls on the testing.T/F parameter (eg. t.Run, f.Add) are ignored
+		receiver := ""
+		if params := fn.ChildByFieldName("parameters"); params != nil && params.NamedChildCount() > 0 {
+			if param := params.NamedChild(0).ChildByFieldName("name"); param != nil {
+				receiver = param.Content(p.source)
+			}
+		}
+		p.add(name, kind, fn.ChildByFieldName("body"), receiver)
+	}
+}
+
+// java: methods annotated with @Test/@FuzzTest and jazzer's fuzzerTestOneInput
+func (p *testParser) findJava(node *sitter.Node) {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		switch child.Type() {
+		case "class_declaration":
+			p.findJava(child.ChildByFieldName("body"))
+
+		case "method_declaration":
+			name := child.ChildByFieldName("name").Content(p.source)
+			annotations := p.annotations(child)
+			switch {
+			case name == "fuzzerTestOneInput" || slices.ContainsFunc(annotations, isOneOf(javaFuzzAnnotations)):
+				p.add(name, Fuzz, child.ChildByFieldName("body"), "")
+			case slices.ContainsFunc(annotations, isOneOf(javaUnitAnnotations)):
+				p.add(name, Unit, child.ChildByFieldName("body"), "")
+			}
+		}
+	}
+}
+
+// returns the names of all annotations of a java method
+func (p *testParser) annotations(method *sitter.Node) []string {
+	names := []string{}
+	modifiers := helper.FirstChildByType(method, "modifiers")
+	if modifiers == nil {
+		return names
+	}
+	for i := 0; i < int(modifiers.NamedChildCount()); i++ {
+		child := modifiers.NamedChild(i)
+		if child.Type() != "marker_annotation" && child.Type() != "annotation" {
+			continue
+		}
+		if name := child.ChildByFieldName("name"); name != nil {
+			// use the simple name of qualified annotations (eg. @org.junit.Test)
+			parts := strings.Split(name.Content(p.source), ".")
+			names = append(names, parts[len(parts)-1])
+		}
+	}
+	return names
+}
+
+// javascript/typescript: it("name", () => {...}), test("name", ...) and it.fuzz("name", ...),
+// the names of surrounding describe blocks are used as prefix
+func (p *testParser) findJS(node *sitter.Node, prefix string) {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child.Type() != "call_expression" {
+			p.findJS(child, prefix)
+			continue
+		}
+
+		callee := child.ChildByFieldName("function")
+		args := child.ChildByFieldName("arguments")
+		if callee == nil || args == nil || args.NamedChildCount() < 2 {
+			p.findJS(child, prefix)
+			continue
+		}
+
+		name := prefix + p.jsString(args.NamedChild(0))
+		body := args.NamedChild(1)
+		switch fn := p.jsCallee(callee); {
+		case fn == "describe":
+			p.findJS(body, name+" ")
+		case slices.Contains(jsTestFunctions, fn) && strings.HasSuffix(callee.Content(p.source), ".fuzz"):
+			p.add(name, Fuzz, body, "")
+		case slices.Contains(jsTestFunctions, fn):
+			p.add(name, Unit, body, "")
+		default:
+			p.findJS(child, prefix)
+		}
+	}
+}
+
+// returns the name of the called test function, modifiers like it.only/it.fuzz are removed
+func (p *testParser) jsCallee(callee *sitter.Node) string {
+	if callee.Type() == "member_expression" {
+		callee = callee.ChildByFieldName("object")
+	}
+	if callee == nil || callee.Type() != "identifier" {
+		return ""
+	}
+	return callee.Content(p.source)
+}
+
+func (p *testParser) jsString(node *sitter.Node) string {
+	return strings.Trim(node.Content(p.source), "\"'`")
+}
+
+func (p *testParser) add(name string, kind Kind, body *sitter.Node, receiver string) {
+	test := &Test{
+		Name:     name,
+		Kind:     kind,
+		Path:     p.path,
+		Language: p.language,
+		Calls:    []string{},
+		Package:  p.pkg,
+		Imports:  p.imports,
+	}
+	p.collectCalls(body, receiver, test)
+	slog.Debug("found test", "name", name, "kind", kind, "calls", test.Calls)
+	p.tests = append(p.tests, test)
+}
+
+// collects the names of all called functions/methods
+func (p *testParser) collectCalls(node *sitter.Node, receiver string, test *Test) {
+	if node == nil {
+		return
+	}
+
+	var callee *sitter.Node
+	switch node.Type() {
+	case "call_expression":
+		callee = node.ChildByFieldName("function")
+		switch {
+		case callee == nil:
+		case callee.Type() == "selector_expression":
+			// go: ignore calls on the testing parameter
+			if operand := callee.ChildByFieldName("operand"); operand != nil && operand.Content(p.source) == receiver {
+				callee = nil
+			} else {
+				callee = callee.ChildByFieldName("field")
+			}
+		case callee.Type() == "member_expression":
+			callee = callee.ChildByFieldName("property")
+		}
+	case "method_invocation":
+		callee = node.ChildByFieldName("name")
+	case "object_creation_expression":
+		callee = node.ChildByFieldName("type")
+	}
+
+	if callee != nil && (callee.Type() == "identifier" || callee.Type() == "field_identifier" ||
+		callee.Type() == "property_identifier" || callee.Type() == "type_identifier") {
+		name := callee.Content(p.source)
+		if !slices.Contains(test.Calls, name) {
+			test.Calls = append(test.Calls, name)
+		}
+	}
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		p.collectCalls(node.NamedChild(i), receiver, test)
+	}
+}
+
+// returns the name of the package declared by a java file, empty for other languages and the default package
+func javaPackage(root *sitter.Node, source []byte) string {
+	declaration := helper.FirstChildByType(root, "package_declaration")
+	if declaration == nil || declaration.NamedChildCount() == 0 {
+		return ""
+	}
+	return declaration.NamedChild(0).Content(source)
+}
+
+func isOneOf(values []string) func(string) bool {
+	return func(s string) bool {
+		return slices.Contains(values, s)
+	}
+}
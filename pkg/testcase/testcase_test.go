@@ -0,0 +1,80 @@
+package testcase_test
+
+import (
+	"testing"
+
+	"github.com/jochil/gcs/pkg/testcase"
+	"github.com/jochil/gcs/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type expectedTest struct {
+	name  string
+	kind  testcase.Kind
+	calls []string
+}
+
+func TestParse(t *testing.T) {
+	testCases := map[string]struct {
+		path     string
+		language types.Language
+		expected []expectedTest
+	}{
+		"go": {
+			path:     "testdata/parser_test.go",
+			language: types.Go,
+			expected: []expectedTest{
+				{"TestParse", testcase.Unit, []string{"Parse", "Validate"}},
+				{"FuzzParse", testcase.Fuzz, []string{"Parse", "string", "Print"}},
+			},
+		},
+		"java": {
+			path:     "testdata/ParserTest.java",
+			language: types.Java,
+			expected: []expectedTest{
+				{"parse", testcase.Unit, []string{"Parser", "assertEquals", "parse"}},
+				{"encode", testcase.Unit, []string{"encode"}},
+				{"fuzzParse", testcase.Fuzz, []string{"parse", "Parser", "String"}},
+				{"fuzzerTestOneInput", testcase.Fuzz, []string{"decode"}},
+			},
+		},
+		"javascript": {
+			path:     "testdata/parser.test.js",
+			language: types.JavaScript,
+			expected: []expectedTest{
+				{"parser parses numbers", testcase.Unit, []string{"toBe", "expect", "parse"}},
+				{"parser encodes", testcase.Unit, []string{"encode", "prepare"}},
+				{"fuzz parse", testcase.Fuzz, []string{"parse", "toString"}},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tests, err := testcase.Parse(tc.path, tc.language)
+			require.NoError(t, err)
+			require.Len(t, tests, len(tc.expected))
+			for i, expected := range tc.expected {
+				assert.Equal(t, expected.name, tests[i].Name)
+				assert.Equal(t, expected.kind, tests[i].Kind)
+				assert.ElementsMatch(t, expected.calls, tests[i].Calls, "wrong calls for %s", expected.name)
+				assert.Equal(t, tc.path, tests[i].Path)
+			}
+		})
+	}
+}
+
+func TestParse_Imports(t *testing.T) {
+	tests, err := testcase.Parse("testdata/ParserTest.java", types.Java)
+	require.NoError(t, err)
+	require.NotEmpty(t, tests)
+	assert.Equal(t, "com.example", tests[0].Package)
+	assert.Equal(t, "com.example.codec.Encoder", tests[0].Imports["Encoder"])
+
+	tests, err = testcase.Parse("testdata/parser.test.js", types.JavaScript)
+	require.NoError(t, err)
+	require.NotEmpty(t, tests)
+	assert.Empty(t, tests[0].Package)
+	assert.Equal(t, map[string]string{"parse": "./parser", "encode": "./parser"}, tests[0].Imports)
+}
@@ -0,0 +1,33 @@
+package com.example;
+
+import com.code_intelligence.jazzer.junit.FuzzTest;
+import com.example.codec.Encoder;
+import org.junit.jupiter.api.Test;
+
+class ParserTest {
+    @Test
+    void parse() {
+        Parser parser = new Parser();
+        assertEquals(1, parser.parse("1"));
+    }
+
+    @org.junit.jupiter.api.Test
+    public void encode() {
+        Encoder.encode(new byte[]{});
+    }
+
+    @FuzzTest(maxDuration = "10s")
+    void fuzzParse(byte[] data) {
+        new Parser().parse(new String(data));
+    }
+
+    private void helper() {
+        validate();
+    }
+}
+
+class ParseFuzzer {
+    public static void fuzzerTestOneInput(byte[] data) {
+        Parser.decode(data);
+    }
+}
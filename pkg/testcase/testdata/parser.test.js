@@ -0,0 +1,15 @@
+const { parse, encode } = require("./parser");
+
+describe("parser", () => {
+  it("parses numbers", () => {
+    expect(parse("1")).toBe(1);
+  });
+
+  test.only('encodes', function () {
+    encode(utils.prepare());
+  });
+});
+
+it.fuzz("fuzz parse", (data) => {
+  parse(data.toString());
+});
@@ -0,0 +1,30 @@
+package parser
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		if _, err := Parse(""); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+	t.Errorf("%v", Validate(nil))
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add([]byte("abc"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		res, _ := Parse(string(data))
+		res.Print()
+	})
+}
+
+func BenchmarkParse(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Parse("abc")
+	}
+}
+
+func helper(t *testing.T) {
+	Encode(nil)
+}
@@ -0,0 +1,104 @@
+package train
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jochil/gcs/pkg/candidate"
+)
+
+// Label marks a function as positive (eg. fuzzing found a bug) or negative sample
+type Label struct {
+	Path     string `json:"path"`
+	Function string `json:"function"`
+	Label    bool   `json:"label"`
+}
+
+// ReadLabels reads a csv file (columns: path, function, label with an optional header) or a json file
+// (list of objects with path, function and label)
+func ReadLabels(path string) ([]*Label, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		labels := []*Label{}
+		if err := json.NewDecoder(file).Decode(&labels); err != nil {
+			return nil, fmt.Errorf("unable to read labels %s: %w", path, err)
+		}
+		return labels, nil
+	}
+
+	labels, err := readCSV(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read labels %s: %w", path, err)
+	}
+	return labels, nil
+}
+
+func readCSV(r io.Reader) ([]*Label, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	labels := []*Label{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && strings.EqualFold(record[0], "path") {
+			continue
+		}
+		label, err := parseLabel(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		labels = append(labels, &Label{Path: record[0], Function: record[1], Label: label})
+	}
+	return labels, nil
+}
+
+func parseLabel(s string) (bool, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if slices.Contains([]string{"yes", "y", "bug"}, s) {
+		return true, nil
+	}
+	if slices.Contains([]string{"no", "n", ""}, s) {
+		return false, nil
+	}
+	label, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("invalid label %q", s)
+	}
+	return label, nil
+}
+
+// Matches checks if the label belongs to a candidate, the path can be relative (suffix of the candidate path) and
+// the function can be qualified with the class/receiver (eg. MyClass.myMethod)
+func (l *Label) Matches(c *candidate.Candidate) bool {
+	name := c.Function.Name
+	if c.Class != nil && strings.Contains(l.Function, ".") {
+		name = c.Class.Name + "." + name
+	}
+	if name != l.Function {
+		return false
+	}
+	labelPath := filepath.ToSlash(filepath.Clean(l.Path))
+	candidatePath := filepath.ToSlash(filepath.Clean(c.Path))
+	return candidatePath == labelPath || strings.HasSuffix(candidatePath, "/"+labelPath)
+}
@@ -0,0 +1,159 @@
+// Package train fits the weights of the scoring by a logistic regression on labeled candidates (eg. functions
+// in which fuzzing found bugs).
+package train
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/jochil/gcs/pkg/candidate"
+	"github.com/jochil/gcs/pkg/config"
+)
+
+type Options struct {
+	// Iterations of the gradient descent
+	Iterations int
+	// LearningRate of the gradient descent
+	LearningRate float64
+	// L2 is the strength of the regularization, it keeps the weights of rare metrics small
+	L2 float64
+}
+
+var DefaultOptions = Options{
+	Iterations:   5000,
+	LearningRate: 0.5,
+	L2:           0.01,
+}
+
+// Result contains the fitted weights and statistics about the training
+type Result struct {
+	// Weights are scaled, so the largest absolute weight is 10 (the ranking only depends on their ratio)
+	Weights   config.Weights
+	Samples   int
+	Positives int
+	// Unmatched are the labels without a matching candidate
+	Unmatched []*Label
+	// LogLoss and Accuracy are measured on the training samples
+	LogLoss  float64
+	Accuracy float64
+}
+
+// Train fits the weights of all metrics (see config.MetricNames) to the labels, the candidates need to be scored
+// as the normalized metrics are used as features
+func Train(candidates candidate.Candidates, labels []*Label, opts Options) (*Result, error) {
+	result := &Result{Unmatched: []*Label{}}
+	names := config.MetricNames()
+	features := [][]float64{}
+	targets := []float64{}
+
+	for _, l := range labels {
+		matched := false
+		for _, c := range candidates {
+			if !l.Matches(c) {
+				continue
+			}
+			matched = true
+			features = append(features, normalized(c, names))
+			if l.Label {
+				targets = append(targets, 1)
+				result.Positives++
+			} else {
+				targets = append(targets, 0)
+			}
+		}
+		if !matched {
+			slog.Warn("no candidate found for label", "path", l.Path, "func", l.Function)
+			result.Unmatched = append(result.Unmatched, l)
+		}
+	}
+	result.Samples = len(targets)
+
+	if result.Samples == 0 {
+		return nil, errors.New("no labeled candidates found")
+	}
+	if result.Positives == 0 || result.Positives == result.Samples {
+		return nil, fmt.Errorf("positive and negative labels are required, found %d of %d positive", result.Positives, result.Samples)
+	}
+
+	weights, bias := Fit(features, targets, opts)
+	result.LogLoss, result.Accuracy = evaluate(features, targets, weights, bias)
+
+	scale := 0.0
+	for _, w := range weights {
+		scale = math.Max(scale, math.Abs(w))
+	}
+	result.Weights = config.Weights{}
+	for i, name := range names {
+		w := 0.0
+		// small negative weights are rounded to 0 instead of -0
+		if scale > 0 && math.Abs(weights[i]/scale) >= 0.0005 {
+			w = math.Round(weights[i]/scale*1000) / 100
+		}
+		result.Weights[name] = w
+	}
+	return result, nil
+}
+
+// returns the normalized metrics of a candidate in the order of the given metric names
+func normalized(c *candidate.Candidate, names []string) []float64 {
+	values := map[string]float64{}
+	for _, contribution := range c.Contributions {
+		values[contribution.Metric] = contribution.Normalized
+	}
+	features := make([]float64, len(names))
+	for i, name := range names {
+		features[i] = values[name]
+	}
+	return features
+}
+
+// Fit runs a logistic regression with batch gradient descent and L2 regularization
+func Fit(features [][]float64, targets []float64, opts Options) (weights []float64, bias float64) {
+	if len(features) == 0 {
+		return nil, 0
+	}
+	n := float64(len(features))
+	weights = make([]float64, len(features[0]))
+
+	for iteration := 0; iteration < opts.Iterations; iteration++ {
+		gradients := make([]float64, len(weights))
+		gradientBias := 0.0
+		for i, x := range features {
+			diff := predict(x, weights, bias) - targets[i]
+			for j := range weights {
+				gradients[j] += diff * x[j]
+			}
+			gradientBias += diff
+		}
+		for j := range weights {
+			weights[j] -= opts.LearningRate * (gradients[j]/n + opts.L2*weights[j])
+		}
+		bias -= opts.LearningRate * gradientBias / n
+	}
+	return weights, bias
+}
+
+func predict(x, weights []float64, bias float64) float64 {
+	z := bias
+	for j, w := range weights {
+		z += w * x[j]
+	}
+	return 1 / (1 + math.Exp(-z))
+}
+
+// returns the log loss and accuracy of the model
+func evaluate(features [][]float64, targets, weights []float64, bias float64) (logLoss, accuracy float64) {
+	const eps = 1e-15
+	correct := 0
+	for i, x := range features {
+		p := math.Min(math.Max(predict(x, weights, bias), eps), 1-eps)
+		logLoss -= targets[i]*math.Log(p) + (1-targets[i])*math.Log(1-p)
+		if (p >= 0.5) == (targets[i] == 1) {
+			correct++
+		}
+	}
+	n := float64(len(features))
+	return logLoss / n, float64(correct) / n
+}
@@ -0,0 +1,79 @@
+package train_test
+
+import (
+	"testing"
+
+	"github.com/jochil/gcs/pkg/config"
+	"github.com/jochil/gcs/pkg/search"
+	"github.com/jochil/gcs/pkg/train"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLabels(t *testing.T) {
+	labels, err := train.ReadLabels("testdata/labels.csv")
+	require.NoError(t, err)
+	require.Len(t, labels, 6)
+	assert.Equal(t, &train.Label{Path: "main.go", Function: "Parser.Parse", Label: true}, labels[0])
+	assert.True(t, labels[1].Label)
+	assert.False(t, labels[3].Label)
+
+	labels, err = train.ReadLabels("testdata/labels.json")
+	require.NoError(t, err)
+	require.Len(t, labels, 2)
+	assert.Equal(t, &train.Label{Path: "main.go", Function: "Run", Label: false}, labels[1])
+
+	_, err = train.ReadLabels("testdata/invalid.csv")
+	require.Error(t, err)
+	_, err = train.ReadLabels("testdata/missing.csv")
+	require.Error(t, err)
+}
+
+func TestFit(t *testing.T) {
+	// the first feature decides the label, the second one is noise
+	features := [][]float64{{1, 0}, {0.9, 1}, {0.8, 0}, {0.1, 1}, {0, 0}, {0.2, 1}}
+	targets := []float64{1, 1, 1, 0, 0, 0}
+
+	weights, _ := train.Fit(features, targets, train.DefaultOptions)
+	require.Len(t, weights, 2)
+	assert.Greater(t, weights[0], 1.0)
+	assert.Less(t, weights[1], weights[0]/5)
+}
+
+func TestTrain(t *testing.T) {
+	candidates, err := search.Search([]string{"../callgraph/testdata/golang"})
+	require.NoError(t, err)
+	labels, err := train.ReadLabels("testdata/labels.csv")
+	require.NoError(t, err)
+
+	result, err := train.Train(candidates, labels, train.DefaultOptions)
+	require.NoError(t, err)
+	assert.Equal(t, 5, result.Samples)
+	assert.Equal(t, 2, result.Positives)
+	require.Len(t, result.Unmatched, 1)
+	assert.Equal(t, "Missing", result.Unmatched[0].Function)
+	assert.Equal(t, 1.0, result.Accuracy)
+
+	require.Len(t, result.Weights, len(config.MetricNames()))
+	maxWeight := 0.0
+	for _, w := range result.Weights {
+		maxWeight = max(maxWeight, w, -w)
+	}
+	assert.Equal(t, 10.0, maxWeight)
+
+	// the trained weights are a valid profile
+	cfg := &config.Config{Profiles: map[string]*config.Profile{"trained": {Weights: result.Weights}}}
+	_, err = cfg.Scoring("trained")
+	require.NoError(t, err)
+}
+
+func TestTrain_Invalid(t *testing.T) {
+	candidates, err := search.Search([]string{"../callgraph/testdata/golang"})
+	require.NoError(t, err)
+
+	_, err = train.Train(candidates, []*train.Label{{Path: "missing.go", Function: "Run", Label: true}}, train.DefaultOptions)
+	require.Error(t, err)
+
+	_, err = train.Train(candidates, []*train.Label{{Path: "main.go", Function: "Run", Label: true}}, train.DefaultOptions)
+	require.Error(t, err)
+}